@@ -0,0 +1,111 @@
+/*
+ * aggro.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"math"
+
+	"github.com/isangeles/flame/effect"
+)
+
+// SetNoticeFunc sets function for checking if NPC notices
+// specified target.
+// The function is called only for targets in the NPC aggro range
+// and arc, so it can be used to account for stealth or invisibility
+// mechanics not covered by NPC profile.
+func (ai *AI) SetNoticeFunc(f func(npc *Character, tar effect.Target) bool) {
+	ai.noticeFunc = f
+}
+
+// noticed checks if specified NPC notices specified target.
+func (ai *AI) noticed(npc *Character, tar effect.Target) bool {
+	npcX, npcY := npc.Position()
+	tarX, tarY := tar.Position()
+	if math.Hypot(tarX-npcX, tarY-npcY) > npc.AggroRange(tar) {
+		return false
+	}
+	if !npc.inAggroArc(tarX, tarY) {
+		return false
+	}
+	if stealthed(npc.Profile(), tar) {
+		return false
+	}
+	if ai.noticeFunc != nil {
+		return ai.noticeFunc(npc, tar)
+	}
+	return true
+}
+
+// AggroRange returns aggro range of the character for specified
+// target.
+// Range is reduced for each target level above the character level,
+// as defined in the character profile.
+// If specified target is nil, the base aggro range is returned.
+func (c *Character) AggroRange(tar effect.Target) float64 {
+	p := c.Profile()
+	r := p.AggroRange
+	if r <= 0 {
+		r = c.SightRange()
+	}
+	if tar, ok := tar.(interface{ Level() int }); ok && p.AggroLevelFactor > 0 {
+		diff := tar.Level() - c.Level()
+		if diff > 0 {
+			r -= r * p.AggroLevelFactor * float64(diff)
+		}
+	}
+	return math.Max(r, 0)
+}
+
+// inAggroArc checks if specified position is in the
+// aggro arc in front of the character.
+func (c *Character) inAggroArc(x, y float64) bool {
+	arc := c.Profile().AggroArc
+	if arc <= 0 || arc >= 360 {
+		return true
+	}
+	posX, posY := c.Position()
+	if x == posX && y == posY {
+		return true
+	}
+	angle := math.Atan2(y-posY, x-posX) - c.Facing()
+	angle = math.Abs(math.Remainder(angle, 2*math.Pi))
+	return angle <= arc/2*math.Pi/180
+}
+
+// stealthed checks if specified target is under any
+// of the stealth effects from specified profile.
+func stealthed(p *Profile, tar effect.Target) bool {
+	tarEffects, ok := tar.(interface{ Effects() []*effect.Effect })
+	if !ok {
+		return false
+	}
+	for _, e := range tarEffects.Effects() {
+		for _, id := range p.StealthEffects {
+			if e.ID() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -26,29 +26,63 @@
 package ai
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"math/rand"
+	"time"
 
-	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/dialog"
 	"github.com/isangeles/flame/effect"
 	"github.com/isangeles/flame/objects"
 	"github.com/isangeles/flame/req"
-	"github.com/isangeles/flame/rng"
 	"github.com/isangeles/flame/skill"
 
 	"github.com/isangeles/ignite/config"
 )
 
+// Break between AI updates in the AI loop.
+const UpdateBreak = 16 * time.Millisecond
+
 // Struct for controlling non-player characters.
 type AI struct {
-	game      *Game
-	moveTimer int64
-	chatTimer int64
+	game       *Game
+	moveTimer  int64
+	chatTimer  int64
+	noticeFunc func(npc *Character, tar effect.Target) bool
+	answered   map[*dialog.Dialog]*dialog.Stage
+	attacks    map[string]attack
+	logger     *slog.Logger
+	// Source for all random AI decisions.
+	seed int64
+	rand *rand.Rand
+	// Decision trace, number of updates and time of updates.
+	trace *Trace
+	tick  int64
+	time  int64
+	// Time since last reputation save.
+	reputationTimer int64
+	reputationPath  string
+	// Time since last state save.
+	stateTimer int64
+	statePath  string
 }
 
 // New creates new AI for specified game.
 func New(game *Game) *AI {
 	ai := new(AI)
 	ai.game = game
+	ai.logger = game.Logger()
+	ai.answered = make(map[*dialog.Dialog]*dialog.Stage)
+	ai.attacks = make(map[string]attack)
+	ai.reputationPath = game.Config().ReputationPath
+	ai.statePath = game.Config().StatePath
+	seed := game.Config().Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	ai.SetSeed(seed)
+	ai.Logger().Info("AI created", "seed", seed)
 	return ai
 }
 
@@ -57,70 +91,214 @@ func (ai *AI) Update(delta int64) {
 	if ai.game.paused {
 		return
 	}
+	start := time.Now()
+	tuning := ai.Game().Config().Tuning()
+	ai.tick++
+	ai.time += delta
 	ai.moveTimer += delta
 	ai.chatTimer += delta
+	// Groups.
+	ai.updateGroups()
+	// Escorts.
+	ai.updateEscorts(delta)
+	// Dialogs.
+	ai.updateDialogs()
+	// Merchants.
+	ai.updateEconomy(delta)
+	// Reputation.
+	ai.updateReputation(delta)
+	// State.
+	ai.updateState(delta)
 	// NPCs.
 	for _, npc := range ai.Game().Characters() {
-		// Move around.
-		if ai.moveTimer >= config.MoveFreq {
-			if npc.Casted() != nil || npc.Moving() || npc.Fighting() || npc.Agony() {
-				continue
-			}
-			posX, posY := npc.Position()
-			defX, defY := npc.DefaultPosition()
-			if posX != defX || posY != defY {
-				npc.SetDestPoint(defX, defY)
-				continue
-			}
-			ai.moveAround(npc)
+		entry := ai.traceEntry(npc)
+		ai.updateNPC(npc, delta, tuning, entry)
+		ai.writeTrace(entry)
+	}
+	// Reset timers.
+	if ai.moveTimer >= tuning.MoveFreq {
+		ai.moveTimer = 0
+	}
+	if ai.chatTimer >= tuning.ChatFreq {
+		ai.chatTimer = 0
+	}
+	// Metrics.
+	ai.updateMetrics(time.Since(start))
+}
+
+// updateNPC updates specified NPC with specified time delta and
+// tuning values, decisions are added to specified trace entry.
+func (ai *AI) updateNPC(npc *Character, delta int64, tuning config.Tuning, entry *TraceEntry) {
+	// Flee after group leader death.
+	if npc.updateFlee(delta) {
+		entry.decide(ActionFlee, ReasonLeaderDeath)
+		return
+	}
+	// Keep group formation.
+	group := ai.Game().CharacterGroup(npc)
+	var leader *Character
+	if group != nil {
+		leader = group.Leader()
+	}
+	following := leader != nil && leader != npc
+	if following && !npc.Fighting() && !npc.Agony() {
+		ai.keepFormation(npc, group)
+		entry.decide(ActionFollow, ReasonGroupFormation)
+	}
+	// Stay in place during dialog.
+	talking := npc.InDialog()
+	escorting := npc.Escort() != nil
+	// Move around.
+	if ai.moveTimer >= tuning.MoveFreq && !following && !talking && !escorting {
+		if npc.Casted() != nil || npc.Moving() || npc.Fighting() || npc.Agony() {
+			entry.decide(ActionNone, ReasonBusy)
+			return
 		}
-		// Random chat.
-		if ai.chatTimer >= config.ChatFreq {
-			if npc.Casted() != nil || npc.Moving() || npc.Fighting() || npc.Agony() {
-				continue
-			}
-			ai.saySomething(npc)
+		if ai.loot(npc) {
+			entry.decide(ActionLoot, ReasonLootInRange)
+			return
 		}
-		// Combat.
-		if !npc.hasHostileTarget() {
-			// Look for hostile target.
-			var tar effect.Target
-			area := ai.Game().Chapter().ObjectArea(npc)
-			if area == nil {
+		posX, posY := npc.Position()
+		defX, defY := npc.Anchor()
+		if posX != defX || posY != defY {
+			npc.SetDestPoint(defX, defY)
+			entry.decide(ActionReturn, ReasonAwayFromAnchor)
+			return
+		}
+		ai.moveAround(npc)
+		entry.decide(ActionMoveAround, ReasonMoveTimer)
+	}
+	// Random chat.
+	if ai.chatTimer >= tuning.ChatFreq && !talking {
+		if npc.Casted() != nil || npc.Moving() || npc.Fighting() || npc.Agony() {
+			entry.decide(ActionNone, ReasonBusy)
+			return
+		}
+		ai.saySomething(npc)
+		entry.decide(ActionChat, ReasonChatTimer)
+	}
+	// Combat.
+	if !npc.hasHostileTarget() {
+		// Look for hostile target.
+		var tar effect.Target
+		area := ai.Game().Chapter().ObjectArea(npc)
+		if area == nil {
+			entry.decide(ActionNone, ReasonNoArea)
+			return
+		}
+		npcX, npcY := npc.Position()
+		for _, o := range area.NearObjects(npcX, npcY, npc.AggroRange(nil)) {
+			if o == npc.Character {
 				continue
 			}
-			npcX, npcY := npc.Position()
-			for _, o := range area.NearObjects(npcX, npcY, npc.SightRange()) {
-				if o == npc.Character {
-					continue
-				}
-				if npc.AttitudeFor(o) == character.Hostile {
-					tar = o
-					break
-				}
+			hostile := npc.Hostile(o)
+			noticed := hostile && ai.noticed(npc, o)
+			entry.addCandidate(npc, o, hostile, noticed)
+			if noticed {
+				tar = o
+				break
 			}
-			if tar == nil {
-				continue
-			}
-			npc.SetTarget(tar)
 		}
-		if npc.hasHostileTarget() && (!targetLive(npc.Targets()[0]) || npc.DefPosDistance() > config.DeaggroDis) {
-			npc.SetTarget(nil)
+		if tar == nil {
+			entry.decide(ActionNone, ReasonNoHostile)
+			return
 		}
-		if npc.Fighting() {
-			ai.fight(npc)
+		npc.SetTarget(tar)
+		entry.decide(ActionTarget, ReasonHostileNoticed)
+	}
+	// Group followers disengage on leader distance from its anchor position,
+	// escorts disengage only on escort give up.
+	deaggroDis := npc.AnchorDistance()
+	switch {
+	case escorting:
+		deaggroDis = 0
+	case following:
+		deaggroDis = leader.AnchorDistance()
+	}
+	if npc.hasHostileTarget() && (!targetLive(npc.Targets()[0]) || deaggroDis > tuning.DeaggroDis) {
+		reason := ReasonDeaggro
+		if !targetLive(npc.Targets()[0]) {
+			reason = ReasonTargetDead
 		}
-		break
+		npc.SetTarget(nil)
+		entry.decide(ActionUntarget, reason)
 	}
-	// Reset timers.
-	if ai.moveTimer >= config.MoveFreq {
-		ai.moveTimer = 0
+	// Fight with hostile target, also if the target is hostile
+	// only because of the reputation or escort.
+	if npc.hasHostileTarget() {
+		ai.fight(npc, entry)
 	}
-	if ai.chatTimer >= config.ChatFreq {
-		ai.chatTimer = 0
+}
+
+// Run updates the AI and the AI game in short intervals until
+// specified context is done.
+func (ai *AI) Run(ctx context.Context) error {
+	return Loop(ctx, func(delta int64) bool {
+		ai.Update(delta)
+		ai.Game().Update(delta)
+		return true
+	})
+}
+
+// Loop triggers specified update function with time in milliseconds
+// since the last update, after each update break, until specified
+// context is done or the function returns false.
+// Returns context error if the loop was stopped by the context.
+func Loop(ctx context.Context, update func(delta int64) bool) error {
+	ticker := time.NewTicker(UpdateBreak)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		delta := time.Since(last).Milliseconds()
+		last = time.Now()
+		if !update(delta) {
+			return nil
+		}
 	}
 }
 
+// SetReputationPath sets path to the file for saving reputation,
+// reputation is not saved if path is empty.
+func (ai *AI) SetReputationPath(path string) {
+	ai.reputationPath = path
+}
+
+// SetStatePath sets path to the file for saving AI state,
+// state is not saved if path is empty.
+func (ai *AI) SetStatePath(path string) {
+	ai.statePath = path
+}
+
+// SetSeed resets source of the AI random decisions with
+// specified seed.
+// AI with the same seed makes the same decisions for the same
+// game state.
+func (ai *AI) SetSeed(seed int64) {
+	ai.seed = seed
+	ai.rand = rand.New(rand.NewSource(seed))
+}
+
+// Seed returns seed of the AI random decisions.
+func (ai *AI) Seed() int64 {
+	return ai.seed
+}
+
+// SetLogger sets logger for AI messages.
+func (ai *AI) SetLogger(l *slog.Logger) {
+	ai.logger = l
+}
+
+// Logger returns logger for AI messages, by default logger of
+// the AI game.
+func (ai *AI) Logger() *slog.Logger {
+	return ai.logger
+}
+
 // Game returns AI game.
 func (ai *AI) Game() *Game {
 	return ai.game
@@ -128,7 +306,7 @@ func (ai *AI) Game() *Game {
 
 // moveAround moves specified character in random direction.
 func (ai *AI) moveAround(npc *Character) {
-	dir := rng.RollInt(1, 4)
+	dir := ai.rand.Intn(4) + 1
 	posX, posY := npc.Position()
 	switch dir {
 	case 1:
@@ -150,32 +328,49 @@ func (ai *AI) saySomething(npc *Character) {
 }
 
 // fight selects proper combat skill and uses it on the current target of specified NPC.
-func (ai *AI) fight(npc *Character) {
+// Decisions are added to specified trace entry.
+func (ai *AI) fight(npc *Character, entry *TraceEntry) {
 	tar := npc.Targets()[0]
-	skill := combatSkill(npc, npc.Targets()[0])
+	skill, scores := combatSkill(npc, npc.Targets()[0])
+	entry.addSkills(npc, skill, scores)
 	if skill == nil {
+		entry.decide(ActionNone, ReasonNoSkill)
 		return
 	}
 	if !npc.meetTargetRangeReqs(skill.UseAction().Requirements()...) {
 		destPosX, destPosY := tar.Position()
 		npc.MoveCloseTo(destPosX, destPosY, minRange(skill))
+		entry.decide(ActionApproach, ReasonOutOfRange)
 		return
 	}
 	if npc.Cooldown() > 0 || npc.Casted() != nil {
+		entry.decide(ActionNone, ReasonCooldown)
 		return
 	}
 	npc.Use(skill)
+	entry.decide(ActionUseSkill, ReasonSkillReady)
 }
 
-// combatSkill selects NPC skill to use in combat or nil if specified
-// NPC has no suitable skills to use in combat.
-func combatSkill(npc *Character, tar effect.Target) *skill.Skill {
-	for _, s := range npc.Skills() {
+// combatSkill selects NPC skill with the highest score to use in
+// combat or nil if specified NPC has no suitable skills to use in
+// combat.
+// Returns also scores of all NPC skills in the skills order, ready
+// skills are scored by their order, so the first ready skill is
+// selected, and not ready skills have zero score.
+func combatSkill(npc *Character, tar effect.Target) (selected *skill.Skill, scores []float64) {
+	skills := npc.Skills()
+	best := 0.0
+	for i, s := range skills {
+		score := 0.0
 		if s.UseAction() != nil && s.UseAction().Cooldown() <= 0 {
-			return s
+			score = float64(len(skills) - i)
+		}
+		scores = append(scores, score)
+		if score > best {
+			best, selected = score, s
 		}
 	}
-	return nil
+	return
 }
 
 // minRange returns minimal required range for specified skill.
@@ -24,7 +24,11 @@
 package ai
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/isangeles/flame"
 	"github.com/isangeles/flame/character"
@@ -36,14 +40,95 @@ import (
 // TestUpdateMoveAround test moving around by AI.
 func TestUpdateMoveAronud(t *testing.T) {
 	mod := flame.NewModule(res.ModuleData{})
-	game := NewGame(mod)
+	game := NewGame(mod, nil)
 	char := NewCharacter(character.New(charData), game)
 	game.AddCharacter(char)
 	ai := New(game)
-	ai.Update(config.MoveFreq)
+	ai.Update(game.Config().Tuning().MoveFreq)
 	posX, posY := char.Position()
 	destX, destY := char.DestPoint()
 	if posX == destX && posY == destY {
 		t.Fatalf("Character was not moved")
 	}
 }
+
+// TestRun tests running AI loop until context is done.
+func TestRun(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	ai := New(game)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*UpdateBreak)
+	defer cancel()
+	start := time.Now()
+	err := ai.Run(ctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Invalid run error: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("AI loop not stopped on context done")
+	}
+}
+
+// TestSeed tests AI random decisions with the same seed.
+func TestSeed(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	conf := config.Default()
+	conf.Seed = 42
+	ai1, ai2 := New(NewGame(mod, conf)), New(NewGame(mod, conf))
+	if ai1.Seed() != 42 {
+		t.Fatalf("Invalid seed: %d != 42", ai1.Seed())
+	}
+	for i := 0; i < 10; i++ {
+		if ai1.rand.Int63() != ai2.rand.Int63() {
+			t.Fatalf("Different random decisions with the same seed")
+		}
+	}
+}
+
+// TestUpdateAllNPCs tests updating all NPCs with hostile targets
+// in single AI update.
+func TestUpdateAllNPCs(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	game.AddProfile(&Profile{
+		ID:              "guard",
+		Characters:      []string{"npc1", "npc2"},
+		Faction:         "town",
+		HostileStanding: -100,
+	})
+	game.SetReputation(NewReputation())
+	player := character.New(charData)
+	game.Reputation().Change("town", player, -200)
+	for _, id := range []string{"npc1", "npc2"} {
+		data := charData
+		data.ID = id
+		npc := NewCharacter(character.New(data), game)
+		game.AddCharacter(npc)
+		npc.SetTarget(player)
+	}
+	ai := New(game)
+	buf := new(bytes.Buffer)
+	ai.SetTrace(NewTrace(buf))
+	ai.Update(16)
+	dec := json.NewDecoder(buf)
+	updated := make(map[string]TraceEntry)
+	for dec.More() {
+		var e TraceEntry
+		err := dec.Decode(&e)
+		if err != nil {
+			t.Fatalf("Unable to decode trace entry: %v", err)
+		}
+		updated[e.ID] = e
+	}
+	if len(updated) != 2 {
+		t.Fatalf("Invalid number of updated NPCs: %d != 2", len(updated))
+	}
+	for id, e := range updated {
+		if len(e.Targets) != 1 {
+			t.Errorf("NPC %s: target lost", id)
+		}
+		if len(e.Skills) != len(updated["npc1"].Skills) || len(e.Decisions) != len(updated["npc1"].Decisions) {
+			t.Errorf("NPC %s: different decisions than NPC npc1: %v", id, e.Decisions)
+		}
+	}
+}
@@ -24,8 +24,9 @@
 package ai
 
 import (
-	"log"
+	"log/slog"
 	"math"
+	"sync"
 
 	"github.com/isangeles/flame/character"
 	"github.com/isangeles/flame/effect"
@@ -40,8 +41,19 @@ import (
 // Wrapper struct for AI character.
 type Character struct {
 	*character.Character
-	game        *Game
-	onUseEvents []func(o useaction.Usable)
+	game         *Game
+	facing       float64
+	tradePolicy  TradePolicy
+	tradeSpent   int
+	tradeMutex   sync.Mutex
+	restockTimer int64
+	escort       *Escort
+	flee         *flee
+	// Escort started by the escort flag.
+	escortFlagUsed bool
+	// Last dialog partner.
+	lastTalkerID, lastTalkerSerial string
+	onUseEvents                    []func(o useaction.Usable)
 }
 
 // NewCharacter creates new game character.
@@ -61,6 +73,10 @@ func (c *Character) AddOnUseEvent(event func(o useaction.Usable)) {
 // SetDestPoint sets a specified XY position as current
 // as a character destination point.
 func (c *Character) SetDestPoint(x, y float64) {
+	posX, posY := c.Position()
+	if x != posX || y != posY {
+		c.facing = math.Atan2(y-posY, x-posX)
+	}
 	c.Character.SetDestPoint(x, y)
 	if c.game.Server() == nil {
 		return
@@ -69,8 +85,7 @@ func (c *Character) SetDestPoint(x, y float64) {
 	req := request.Request{Move: []request.Move{moveReq}}
 	err := c.game.Server().Send(req)
 	if err != nil {
-		log.Printf("Character: %s %s: unable to send move request: %v",
-			c.ID(), c.Serial(), err)
+		c.logger().Error("unable to send request", "request", "move", "error", err)
 	}
 }
 
@@ -84,8 +99,7 @@ func (c *Character) AddChatMessage(message string) {
 	req := request.Request{Chat: []request.Chat{chatReq}}
 	err := c.game.Server().Send(req)
 	if err != nil {
-		log.Printf("Character: %s %s: unable to send chat request: %v",
-			c.ID(), c.Serial(), err)
+		c.logger().Error("unable to send request", "request", "chat", "error", err)
 	}
 }
 
@@ -105,8 +119,7 @@ func (c *Character) SetTarget(tar effect.Target) {
 	req := request.Request{Target: []request.Target{targetReq}}
 	err := c.game.Server().Send(req)
 	if err != nil {
-		log.Printf("Character: %s %s: unable to send target request to the server: %v",
-			c.ID(), c.Serial(), err)
+		c.logger().Error("unable to send request", "request", "target", "error", err)
 	}
 }
 
@@ -136,8 +149,7 @@ func (c *Character) Use(ob useaction.Usable) {
 	req := request.Request{Use: []request.Use{useReq}}
 	err = c.game.Server().Send(req)
 	if err != nil {
-		log.Printf("Character: %s %s: unable to send use request: %v",
-			c.ID(), c.Serial(), err)
+		c.logger().Error("unable to send request", "request", "use", "error", err)
 	}
 }
 
@@ -155,9 +167,29 @@ func (c *Character) MoveCloseTo(x, y, minRange float64) {
 	case y < charY:
 		y += minRange
 	}
+	// Don't repeat move request if the character already moves
+	// to the same point.
+	if destX, destY := c.DestPoint(); c.Moving() && destX == x && destY == y {
+		return
+	}
 	c.SetDestPoint(x, y)
 }
 
+// Facing returns angle(in radians) of the direction
+// of the last character movement.
+func (c *Character) Facing() float64 {
+	return c.facing
+}
+
+// Profile returns behavior profile of the character.
+func (c *Character) Profile() *Profile {
+	p := c.game.CharacterProfile(c.ID())
+	if p == nil {
+		return defaultProfile
+	}
+	return p
+}
+
 // Retruns distance from the character default position.
 func (c *Character) DefPosDistance() float64 {
 	posX, posY := c.Position()
@@ -165,10 +197,15 @@ func (c *Character) DefPosDistance() float64 {
 	return math.Hypot(posX-defX, posY-defY)
 }
 
+// logger returns game logger with character ID and serial.
+func (c *Character) logger() *slog.Logger {
+	return c.game.Logger().With("char-id", c.ID(), "char-serial", c.Serial())
+}
+
 // hasHostileTarget checks if character first target is
 // hostile.
 func (c *Character) hasHostileTarget() bool {
-	return len(c.Targets()) > 0 && c.AttitudeFor(c.Targets()[0]) == character.Hostile
+	return len(c.Targets()) > 0 && c.Hostile(c.Targets()[0])
 }
 
 // meetTargetRangeReqs check if all target range requirements are meet.
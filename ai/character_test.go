@@ -50,7 +50,7 @@ var (
 // function for the game character.
 func TestCharAddOnUseEvent(t *testing.T) {
 	mod := flame.NewModule(res.ModuleData{})
-	game := NewGame(mod)
+	game := NewGame(mod, nil)
 	char := NewCharacter(character.New(charData), game)
 	usable := skill.New(skillData)
 	char.AddSkill(usable)
@@ -0,0 +1,160 @@
+/*
+ * dialog.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"github.com/isangeles/flame/dialog"
+
+	"github.com/isangeles/fire/request"
+)
+
+// updateDialogs answers all module dialogs with AI characters
+// as dialog targets, and saves dialog partners of AI characters.
+func (ai *AI) updateDialogs() {
+	if ai.Game().Chapter() == nil {
+		return
+	}
+	// Remember dialog partners of AI characters.
+	for _, npc := range ai.Game().Characters() {
+		for _, d := range npc.Dialogs() {
+			if d.Target() != nil && !d.Finished() {
+				npc.lastTalkerID, npc.lastTalkerSerial = d.Target().ID(), d.Target().Serial()
+			}
+		}
+	}
+	for _, owner := range ai.Game().Chapter().Characters() {
+		for _, d := range owner.Dialogs() {
+			ai.answerDialog(d)
+		}
+	}
+}
+
+// answerDialog answers current stage of specified dialog if an AI
+// character is the dialog target and the stage was not answered yet.
+// Answered stage is forgotten after the dialog is finished, so the
+// dialog is answered again after restart.
+func (ai *AI) answerDialog(d *dialog.Dialog) {
+	if d.Target() == nil || d.Finished() {
+		delete(ai.answered, d)
+		return
+	}
+	if d.Stage() == nil {
+		return
+	}
+	npc := ai.Game().character(d.Target())
+	if npc == nil {
+		return
+	}
+	if ai.answered[d] == d.Stage() {
+		return
+	}
+	ai.answered[d] = d.Stage()
+	answer := dialogAnswer(npc, d.Stage())
+	if answer == nil {
+		npc.EndDialog(d)
+		delete(ai.answered, d)
+		return
+	}
+	npc.AnswerDialog(d, answer)
+}
+
+// dialogAnswer returns first answer from specified dialog stage
+// with all requirements meet by specified NPC, or nil if there is
+// no such answer.
+func dialogAnswer(npc *Character, stage *dialog.Stage) *dialog.Answer {
+	for _, a := range stage.Answers() {
+		if npc.MeetReqs(a.Requirements()...) {
+			return a
+		}
+	}
+	return nil
+}
+
+// InDialog checks if the character participates in any unfinished
+// dialog, either as dialog owner or target.
+func (c *Character) InDialog() bool {
+	for _, d := range c.Dialogs() {
+		if d.Target() != nil && !d.Finished() {
+			return true
+		}
+	}
+	if c.game.Chapter() == nil {
+		return false
+	}
+	for _, owner := range c.game.Chapter().Characters() {
+		for _, d := range owner.Dialogs() {
+			if d.Target() == nil || d.Finished() {
+				continue
+			}
+			if d.Target().ID() == c.ID() && d.Target().Serial() == c.Serial() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AnswerDialog selects specified answer in specified dialog.
+func (c *Character) AnswerDialog(d *dialog.Dialog, a *dialog.Answer) {
+	answerReq := request.DialogAnswer{
+		Dialog:   dialogRequest(d),
+		AnswerID: a.ID(),
+	}
+	d.Next(a)
+	if c.game.Server() == nil {
+		return
+	}
+	req := request.Request{DialogAnswer: []request.DialogAnswer{answerReq}}
+	err := c.game.Server().Send(req)
+	if err != nil {
+		c.logger().Error("unable to send request", "request", "dialog-answer", "error", err)
+	}
+}
+
+// EndDialog ends specified dialog.
+func (c *Character) EndDialog(d *dialog.Dialog) {
+	endReq := dialogRequest(d)
+	d.Restart()
+	d.SetTarget(nil)
+	if c.game.Server() == nil {
+		return
+	}
+	req := request.Request{DialogEnd: []request.Dialog{endReq}}
+	err := c.game.Server().Send(req)
+	if err != nil {
+		c.logger().Error("unable to send request", "request", "dialog-end", "error", err)
+	}
+}
+
+// dialogRequest creates dialog request for specified dialog.
+func dialogRequest(d *dialog.Dialog) request.Dialog {
+	dialogReq := request.Dialog{DialogID: d.ID()}
+	if d.Owner() != nil {
+		dialogReq.OwnerID, dialogReq.OwnerSerial = d.Owner().ID(), d.Owner().Serial()
+	}
+	if d.Target() != nil {
+		dialogReq.TargetID, dialogReq.TargetSerial = d.Target().ID(), d.Target().Serial()
+	}
+	return dialogReq
+}
@@ -0,0 +1,67 @@
+/*
+ * dialog_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+	"github.com/isangeles/flame/dialog"
+)
+
+// TestAnswerDialog tests answering restarted dialog.
+func TestAnswerDialog(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	npc := NewCharacter(character.New(charData), game)
+	game.AddCharacter(npc)
+	ai := New(game)
+	dialogData := res.DialogData{
+		ID: "dialog",
+		Stages: []res.DialogStageData{
+			{
+				ID:      "stage",
+				Start:   true,
+				Answers: []res.DialogAnswerData{{ID: "answer", End: true}},
+			},
+		},
+	}
+	d := dialog.New(dialogData)
+	d.SetTarget(npc.Character)
+	ai.answerDialog(d)
+	if !d.Finished() {
+		t.Fatalf("Dialog was not answered")
+	}
+	ai.answerDialog(d)
+	if _, ok := ai.answered[d]; ok {
+		t.Errorf("Answered stage not removed after dialog finish")
+	}
+	d.Restart()
+	ai.answerDialog(d)
+	if !d.Finished() {
+		t.Errorf("Dialog was not answered after restart")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ * economy.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"math"
+	"strings"
+
+	"github.com/isangeles/flame/item"
+
+	"github.com/isangeles/fire/request"
+)
+
+const (
+	// Minimal and maximal price multipliers for dynamic pricing.
+	minPriceMultiplier = 0.25
+	maxPriceMultiplier = 4.0
+)
+
+// updateEconomy updates restock timers of all merchants and
+// restocks merchants with elapsed timers.
+func (ai *AI) updateEconomy(delta int64) {
+	for _, npc := range ai.Game().Characters() {
+		p := npc.Profile()
+		if len(p.Restock) < 1 || p.RestockFreq <= 0 {
+			continue
+		}
+		npc.restockTimer += delta
+		if npc.restockTimer < p.RestockFreq {
+			continue
+		}
+		npc.restockTimer = 0
+		npc.Restock()
+	}
+}
+
+// Restock requests the server to add items missing in the
+// character inventory to the quantities from the character
+// profile, and resets spent trade budget of the character.
+// Items are added by the server command, defined by the restock
+// command from the configuration.
+// Without server the character inventory is not changed.
+func (c *Character) Restock() {
+	c.tradeMutex.Lock()
+	c.tradeSpent = 0
+	c.tradeMutex.Unlock()
+	if c.game.Server() == nil {
+		return
+	}
+	commands := make([]string, 0)
+	for id, quantity := range c.Profile().Restock {
+		for i := c.inventoryCount(id); i < quantity; i++ {
+			commands = append(commands, restockCommand(c, id))
+		}
+	}
+	if len(commands) < 1 {
+		return
+	}
+	req := request.Request{Command: commands}
+	err := c.game.Server().Send(req)
+	if err != nil {
+		c.logger().Error("unable to send request", "request", "command", "error", err)
+	}
+}
+
+// ItemPrice returns price of specified item for the character.
+// Price depends on the number of items with the same ID in the
+// character inventory, compared with the restock quantity from
+// the character profile: the price is higher for items in low
+// stock and lower for overstocked items.
+func (c *Character) ItemPrice(it item.Item) float64 {
+	p := c.Profile()
+	quantity, ok := p.Restock[it.ID()]
+	if !ok || p.PriceElasticity <= 0 {
+		return float64(it.Value())
+	}
+	return float64(it.Value()) * priceMultiplier(c.inventoryCount(it.ID()),
+		quantity, p.PriceElasticity)
+}
+
+// priceMultiplier returns price multiplier for item with specified
+// stock and restock quantity.
+func priceMultiplier(stock, quantity int, elasticity float64) float64 {
+	if quantity < 1 {
+		return 1
+	}
+	m := 1 + elasticity*float64(quantity-stock)/float64(quantity)
+	return math.Min(math.Max(m, minPriceMultiplier), maxPriceMultiplier)
+}
+
+// restockCommand creates restock command for specified character
+// and item ID.
+func restockCommand(c *Character, itemID string) string {
+	r := strings.NewReplacer("{id}", c.ID(), "{serial}", c.Serial(),
+		"{item}", itemID)
+	return r.Replace(c.game.Config().RestockCommand)
+}
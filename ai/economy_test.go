@@ -0,0 +1,44 @@
+/*
+ * economy_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"testing"
+)
+
+// TestPriceMultiplier tests dynamic price multiplier.
+func TestPriceMultiplier(t *testing.T) {
+	if m := priceMultiplier(0, 10, 0.5); m != 1.5 {
+		t.Errorf("Invalid multiplier for item out of stock: %f", m)
+	}
+	if m := priceMultiplier(10, 10, 0.5); m != 1 {
+		t.Errorf("Invalid multiplier for item in stock: %f", m)
+	}
+	if m := priceMultiplier(20, 10, 0.5); m != 0.5 {
+		t.Errorf("Invalid multiplier for overstocked item: %f", m)
+	}
+	if m := priceMultiplier(1000, 10, 0.5); m != minPriceMultiplier {
+		t.Errorf("Invalid multiplier for heavily overstocked item: %f", m)
+	}
+}
@@ -0,0 +1,200 @@
+/*
+ * escort.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"math"
+
+	"github.com/isangeles/flame/effect"
+)
+
+const (
+	// ID of the flag that makes NPC escort the character
+	// it last talked with, e.g. flag set by a dialog answer.
+	EscortFlag = "escort"
+	// Distance from the escort distance tolerated by escorts.
+	escortTolerance         = 10.0
+	defEscortDistance       = 50.0
+	defEscortGiveUpDistance = 1000.0
+)
+
+// Struct for escort task of NPC.
+type Escort struct {
+	// ID and serial of the escorted object.
+	LeaderID, LeaderSerial string
+	// Distance kept from the escorted object.
+	Distance float64
+	// Distance from the escorted object after which the
+	// escort is abandoned.
+	GiveUpDistance float64
+	// Time in milliseconds after which the escort is
+	// abandoned, no time limit if zero.
+	Timeout int64
+	timer   int64
+	// Attacker of the escorted object, hostile for the escort
+	// until it dies or the escort ends.
+	attacker effect.Target
+}
+
+// NewEscort creates new escort task for object with specified ID
+// and serial, with default distances.
+func NewEscort(leaderID, leaderSerial string) *Escort {
+	e := Escort{
+		LeaderID:       leaderID,
+		LeaderSerial:   leaderSerial,
+		Distance:       defEscortDistance,
+		GiveUpDistance: defEscortGiveUpDistance,
+	}
+	return &e
+}
+
+// Escort returns current escort task of the character or nil
+// if the character doesn't escort anyone.
+func (c *Character) Escort() *Escort {
+	return c.escort
+}
+
+// SetEscort sets escort task for the character, nil
+// ends the current escort.
+func (c *Character) SetEscort(e *Escort) {
+	c.escort = e
+}
+
+// updateEscorts updates escort tasks of all NPCs.
+func (ai *AI) updateEscorts(delta int64) {
+	for _, npc := range ai.Game().Characters() {
+		ai.checkEscortFlag(npc)
+		e := npc.Escort()
+		if e == nil {
+			continue
+		}
+		e.timer += delta
+		if e.Timeout > 0 && e.timer > e.Timeout {
+			npc.SetEscort(nil)
+			continue
+		}
+		object := ai.Game().Object(e.LeaderID, e.LeaderSerial)
+		leader, ok := object.(effect.Target)
+		if !ok || !targetLive(leader) {
+			npc.SetEscort(nil)
+			continue
+		}
+		leaderX, leaderY := leader.Position()
+		npcX, npcY := npc.Position()
+		dis := math.Hypot(leaderX-npcX, leaderY-npcY)
+		if dis > e.GiveUpDistance {
+			npc.SetEscort(nil)
+			continue
+		}
+		if npc.Agony() {
+			continue
+		}
+		// Defend leader.
+		if e.attacker != nil && !targetLive(e.attacker) {
+			e.attacker = nil
+		}
+		if !npc.hasHostileTarget() {
+			attacker := e.attacker
+			if attacker == nil {
+				attacker = ai.leaderAttacker(npc, leader)
+			}
+			if attacker != nil {
+				npc.defend(attacker)
+			}
+		}
+		if npc.hasHostileTarget() {
+			continue
+		}
+		// Keep distance, wait if leader stops.
+		if dis <= e.Distance+escortTolerance {
+			continue
+		}
+		destX, destY := npc.DestPoint()
+		if math.Hypot(destX-leaderX, destY-leaderY) <= e.Distance+escortTolerance {
+			continue
+		}
+		npc.MoveCloseTo(leaderX, leaderY, e.Distance)
+	}
+}
+
+// checkEscortFlag starts escort of the last dialog partner if
+// specified NPC has escort flag, and ends escort started this way
+// if the flag was removed.
+func (ai *AI) checkEscortFlag(npc *Character) {
+	flagged := false
+	for _, f := range npc.Flags() {
+		if f.ID() == EscortFlag {
+			flagged = true
+			break
+		}
+	}
+	switch {
+	case flagged && !npc.escortFlagUsed && len(npc.lastTalkerID) > 0:
+		e := npc.Profile().escort(npc.lastTalkerID, npc.lastTalkerSerial)
+		npc.SetEscort(e)
+		npc.escortFlagUsed = true
+	case !flagged && npc.escortFlagUsed:
+		npc.SetEscort(nil)
+		npc.escortFlagUsed = false
+	}
+}
+
+// leaderAttacker returns object near specified NPC fighting
+// with specified escorted object, or nil if there is no such
+// object.
+func (ai *AI) leaderAttacker(npc *Character, leader effect.Target) effect.Target {
+	if ai.Game().Chapter() == nil {
+		return nil
+	}
+	area := ai.Game().Chapter().ObjectArea(npc)
+	if area == nil {
+		return nil
+	}
+	npcX, npcY := npc.Position()
+	for _, o := range area.NearObjects(npcX, npcY, npc.AggroRange(nil)) {
+		fighter, ok := o.(interface {
+			Fighting() bool
+			Targets() []effect.Target
+		})
+		if !ok || !fighter.Fighting() || len(fighter.Targets()) < 1 {
+			continue
+		}
+		tar := fighter.Targets()[0]
+		if tar.ID() == leader.ID() && tar.Serial() == leader.Serial() {
+			return o
+		}
+	}
+	return nil
+}
+
+// defend makes the escort fight specified attacker of the escorted
+// object, even if the attacker is not hostile for the escort.
+func (c *Character) defend(attacker effect.Target) {
+	c.escort.attacker = attacker
+	if len(c.Targets()) > 0 && c.Targets()[0].ID() == attacker.ID() &&
+		c.Targets()[0].Serial() == attacker.Serial() {
+		return
+	}
+	c.SetTarget(attacker)
+}
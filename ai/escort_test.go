@@ -0,0 +1,70 @@
+/*
+ * escort_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+)
+
+// TestEscortDefend tests fighting with attacker of the escorted
+// character.
+func TestEscortDefend(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	npc := NewCharacter(character.New(charData), game)
+	game.AddCharacter(npc)
+	attackerData := charData
+	attackerData.ID = "bandit"
+	attacker := character.New(attackerData)
+	npc.SetEscort(NewEscort("player", "0"))
+	npc.defend(attacker)
+	if !npc.hasHostileTarget() || npc.Targets()[0] != attacker {
+		t.Fatalf("Attacker is not hostile target of the escort")
+	}
+	ai := New(game)
+	ai.SetTrace(NewTrace(new(bytes.Buffer)))
+	entry := ai.traceEntry(npc)
+	ai.updateNPC(npc, 16, game.Config().Tuning(), entry)
+	fought := false
+	for _, d := range entry.Decisions {
+		switch d.Reason {
+		case ReasonNoSkill, ReasonOutOfRange, ReasonCooldown, ReasonSkillReady:
+			fought = true
+		case ReasonHostileNoticed, ReasonTargetDead, ReasonDeaggro:
+			t.Errorf("Escort target changed: %s %s", d.Action, d.Reason)
+		}
+	}
+	if !fought {
+		t.Errorf("Escort didn't fight with the attacker: %v", entry.Decisions)
+	}
+	npc.SetEscort(nil)
+	if npc.Hostile(attacker) {
+		t.Errorf("Attacker is hostile after escort end")
+	}
+}
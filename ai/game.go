@@ -24,40 +24,103 @@
 package ai
 
 import (
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/serial"
+
+	"github.com/isangeles/ignite/config"
 )
 
 // Struct for game wrapper.
 type Game struct {
 	*flame.Module
+	conf        *config.Config
 	paused      bool
+	clearRes    bool
 	server      *Server
 	characters  *sync.Map
+	profiles    map[string]*Profile
+	profMutex   sync.RWMutex
+	groups      *sync.Map
+	tradeLog    *TradeLog
+	reputation  *Reputation
+	metrics     *Metrics
+	state       *State
+	stateMutex  sync.Mutex
+	logger      *slog.Logger
+	respMutex   sync.Mutex
 	onLoginFunc func(g *Game)
 }
 
-// NewGame creates new AI game wrapper for specified module.
-func NewGame(module *flame.Module) *Game {
+// NewGame creates new AI game wrapper for specified module
+// and AI configuration, nil configuration means default
+// configuration values.
+func NewGame(module *flame.Module, conf *config.Config) *Game {
+	if conf == nil {
+		conf = config.Default()
+	}
 	g := Game{
 		Module:     module,
+		conf:       conf,
 		characters: new(sync.Map),
+		profiles:   make(map[string]*Profile),
+		groups:     new(sync.Map),
+		logger:     slog.Default(),
 	}
 	return &g
 }
 
 // AddCharacter adds character to control by the game AI.
+// Saved AI state of the character is restored, if available.
+// Character starts escort defined in its profile and joins the first group it matches or groups discovered
+// from the character flags.
 func (g *Game) AddCharacter(c *Character) {
 	g.characters.Store(c.ID()+c.Serial(), c)
+	if p := c.Profile(); len(p.EscortLeaderID) > 0 {
+		c.SetEscort(p.escort(p.EscortLeaderID, p.EscortLeaderSerial))
+	}
+	g.stateMutex.Lock()
+	g.restoreState(c)
+	g.stateMutex.Unlock()
+	for _, grp := range g.Groups() {
+		if grp.matches(c) {
+			grp.addMember(c)
+			return
+		}
+	}
+	for _, f := range c.Flags() {
+		if !strings.HasPrefix(f.ID(), GroupFlagPrefix) {
+			continue
+		}
+		id := strings.TrimPrefix(f.ID(), GroupFlagPrefix)
+		v, _ := g.groups.LoadOrStore(id, NewGroup(id))
+		if grp, ok := v.(*Group); ok {
+			grp.addMember(c)
+		}
+		return
+	}
 }
 
 // RemoveCharacter removes character from game AI control.
+// AI state of the character is kept and restored if the character
+// is added again.
 func (g *Game) RemoveCharacter(c *Character) {
 	g.characters.Delete(c.ID() + c.Serial())
+	g.stateMutex.Lock()
+	if g.state != nil {
+		g.state.Characters[c.ID()+c.Serial()] = c.State()
+	}
+	g.stateMutex.Unlock()
+	if grp := g.CharacterGroup(c); grp != nil {
+		grp.removeMember(c)
+	}
 }
 
-// Character returns game characters.
+// Character returns game characters sorted by ID and serial.
 func (g *Game) Characters() (chars []*Character) {
 	addChar := func(k, v interface{}) bool {
 		char, ok := v.(*Character)
@@ -67,20 +130,180 @@ func (g *Game) Characters() (chars []*Character) {
 		return true
 	}
 	g.characters.Range(addChar)
+	sort.Slice(chars, func(i, j int) bool {
+		return chars[i].ID()+chars[i].Serial() < chars[j].ID()+chars[j].Serial()
+	})
 	return
 }
 
+// AddProfile adds specified NPC behavior profile to the game.
+func (g *Game) AddProfile(p *Profile) {
+	g.profMutex.Lock()
+	defer g.profMutex.Unlock()
+	g.profiles[p.ID] = p
+}
+
+// SetProfiles replaces all game NPC behavior profiles with
+// specified profiles.
+func (g *Game) SetProfiles(profiles []*Profile) {
+	profilesMap := make(map[string]*Profile)
+	for _, p := range profiles {
+		profilesMap[p.ID] = p
+	}
+	g.profMutex.Lock()
+	defer g.profMutex.Unlock()
+	g.profiles = profilesMap
+}
+
+// Profiles returns all game NPC behavior profiles sorted by ID.
+func (g *Game) Profiles() (profiles []*Profile) {
+	g.profMutex.RLock()
+	defer g.profMutex.RUnlock()
+	for _, p := range g.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].ID < profiles[j].ID
+	})
+	return
+}
+
+// CharacterProfile returns behavior profile for character with
+// specified ID.
+// If the character is assigned to many profiles, then profile
+// with the lowest ID is returned.
+// Returns profile with default ID if there is no profile assigned
+// to the character or nil if there is no default profile.
+func (g *Game) CharacterProfile(charID string) *Profile {
+	g.profMutex.RLock()
+	defer g.profMutex.RUnlock()
+	var profile *Profile
+	for _, p := range g.profiles {
+		if profile != nil && profile.ID < p.ID {
+			continue
+		}
+		for _, id := range p.Characters {
+			if id == charID {
+				profile = p
+				break
+			}
+		}
+	}
+	if profile == nil {
+		return g.profiles[DefaultProfileID]
+	}
+	return profile
+}
+
+// character returns AI character for specified object or nil
+// if the object is not controlled by the AI.
+func (g *Game) character(ob serial.Serialer) *Character {
+	v, ok := g.characters.Load(ob.ID() + ob.Serial())
+	if !ok {
+		return nil
+	}
+	char, _ := v.(*Character)
+	return char
+}
+
+// AddGroup adds specified NPC group to the game.
+// Group should be added before its members.
+func (g *Game) AddGroup(grp *Group) {
+	g.groups.Store(grp.ID, grp)
+}
+
+// Groups returns all game NPC groups sorted by ID.
+func (g *Game) Groups() (groups []*Group) {
+	addGroup := func(k, v interface{}) bool {
+		grp, ok := v.(*Group)
+		if ok {
+			groups = append(groups, grp)
+		}
+		return true
+	}
+	g.groups.Range(addGroup)
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ID < groups[j].ID
+	})
+	return
+}
+
+// CharacterGroup returns group of specified character or nil
+// if the character is not a member of any group.
+func (g *Game) CharacterGroup(c *Character) *Group {
+	for _, grp := range g.Groups() {
+		if grp.Member(c) {
+			return grp
+		}
+	}
+	return nil
+}
+
+// Config returns game AI configuration.
+func (g *Game) Config() *config.Config {
+	return g.conf
+}
+
 // SetServer sets remote game server.
 func (g *Game) SetServer(server *Server) {
 	g.server = server
 	g.Server().SetOnResponseFunc(g.handleResponse)
 }
 
+// SetClearResources enables clearing of the flame resources on each
+// module update from the server.
+// Resources are shared by all modules in the process, so this should
+// be enabled only if there is only one game in the process.
+func (g *Game) SetClearResources(clear bool) {
+	g.clearRes = clear
+}
+
 // Server retruns game server.
 func (g *Game) Server() *Server {
 	return g.server
 }
 
+// SetTradeLog sets log for trades evaluated by the AI.
+func (g *Game) SetTradeLog(tl *TradeLog) {
+	g.tradeLog = tl
+}
+
+// TradeLog returns log for trades evaluated by the AI.
+func (g *Game) TradeLog() *TradeLog {
+	return g.tradeLog
+}
+
+// SetReputation sets reputation of players with NPC factions.
+func (g *Game) SetReputation(r *Reputation) {
+	g.reputation = r
+}
+
+// Reputation returns reputation of players with NPC factions.
+func (g *Game) Reputation() *Reputation {
+	return g.reputation
+}
+
+// SetMetrics sets metrics for game AI, metrics are not
+// collected if nil.
+func (g *Game) SetMetrics(m *Metrics) {
+	g.metrics = m
+}
+
+// Metrics returns metrics for game AI.
+func (g *Game) Metrics() *Metrics {
+	return g.metrics
+}
+
+// SetLogger sets logger for game AI messages.
+func (g *Game) SetLogger(l *slog.Logger) {
+	g.logger = l
+}
+
+// Logger returns logger for game AI messages.
+func (g *Game) Logger() *slog.Logger {
+	return g.logger
+}
+
 // SetOnLoginFunc sets function triggered on login.
 func (g *Game) SetOnLoginFunc(f func(g *Game)) {
 	g.onLoginFunc = f
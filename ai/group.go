@@ -0,0 +1,447 @@
+/*
+ * group.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/isangeles/flame/data/text"
+)
+
+const (
+	GroupFileExt = ".group"
+	// Prefix of character flags used to discover groups
+	// from the module data, e.g. characters with 'group_wolves'
+	// flag are members of the 'wolves' group.
+	GroupFlagPrefix = "group_"
+)
+
+// Type for group action on leader death.
+type LeaderDeathAction string
+
+const (
+	NewLeader LeaderDeathAction = "new-leader"
+	Scatter   LeaderDeathAction = "scatter"
+	Flee      LeaderDeathAction = "flee"
+)
+
+// Type for group formation.
+type Formation string
+
+const (
+	Circle Formation = "circle"
+	Line   Formation = "line"
+)
+
+const (
+	defFormationDistance = 30.0
+	defFleeDistance      = 300.0
+	// Distance from formation slot tolerated by followers.
+	formationTolerance = 5.0
+	// Distance from flee point at which NPC stops fleeing.
+	fleeTolerance = 5.0
+	// Time in milliseconds after which NPC stops fleeing.
+	fleeTimeout = 10000
+)
+
+// Struct for group of NPCs with a leader.
+type Group struct {
+	ID string
+	// IDs of member characters.
+	Characters []string
+	// IDs of module flags of member characters.
+	Flags []string
+	// ID and optional serial of the leader character.
+	LeaderID, LeaderSerial string
+	LeaderDeath            LeaderDeathAction
+	Formation              Formation
+	FormationDistance      float64
+	FleeDistance           float64
+	mutex                  sync.RWMutex
+	leader                 *Character
+	members                []*Character
+}
+
+// Struct for flee state of NPC.
+type flee struct {
+	x, y  float64
+	timer int64
+}
+
+// NewGroup creates new group with specified ID and
+// default settings.
+func NewGroup(id string) *Group {
+	g := Group{
+		ID:                id,
+		LeaderDeath:       NewLeader,
+		Formation:         Circle,
+		FormationDistance: defFormationDistance,
+		FleeDistance:      defFleeDistance,
+	}
+	return &g
+}
+
+// UnmarshalGroup parses group from specified reader.
+func UnmarshalGroup(id string, r io.Reader) (*Group, error) {
+	conf, err := text.UnmarshalConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal group config: %v", err)
+	}
+	g := NewGroup(id)
+	g.Characters = conf["characters"]
+	g.Flags = conf["flags"]
+	if len(conf["leader"]) > 0 {
+		g.LeaderID = conf["leader"][0]
+	}
+	if len(conf["leader"]) > 1 {
+		g.LeaderSerial = conf["leader"][1]
+	}
+	if len(conf["leader-death"]) > 0 {
+		g.LeaderDeath = LeaderDeathAction(conf["leader-death"][0])
+		switch g.LeaderDeath {
+		case NewLeader, Scatter, Flee:
+		default:
+			return nil, fmt.Errorf("Invalid leader-death value: %s",
+				g.LeaderDeath)
+		}
+	}
+	if len(conf["formation"]) > 0 {
+		g.Formation = Formation(conf["formation"][0])
+		switch g.Formation {
+		case Circle, Line:
+		default:
+			return nil, fmt.Errorf("Invalid formation value: %s",
+				g.Formation)
+		}
+	}
+	err = confFloat(conf, "formation-distance", &g.FormationDistance)
+	if err != nil {
+		return nil, err
+	}
+	err = confFloat(conf, "flee-distance", &g.FleeDistance)
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ImportGroupsDir imports all groups from files with group
+// extension in directory with specified path.
+// Group ID is a file name without extension.
+func ImportGroupsDir(path string) ([]*Group, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read dir: %v", err)
+	}
+	groups := make([]*Group, 0)
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != GroupFileExt {
+			continue
+		}
+		file, err := os.Open(filepath.Join(path, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to open group file: %v", err)
+		}
+		id := strings.TrimSuffix(f.Name(), GroupFileExt)
+		g, err := UnmarshalGroup(id, file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal group: %s: %v",
+				f.Name(), err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// Leader returns group leader.
+func (g *Group) Leader() *Character {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.leader
+}
+
+// Members returns all group members, including leader.
+func (g *Group) Members() []*Character {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return append([]*Character{}, g.members...)
+}
+
+// Followers returns all group members except the leader.
+func (g *Group) Followers() (followers []*Character) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	for _, m := range g.members {
+		if m != g.leader {
+			followers = append(followers, m)
+		}
+	}
+	return
+}
+
+// Member checks if specified character is a member of the group.
+func (g *Group) Member(c *Character) bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	for _, m := range g.members {
+		if m == c {
+			return true
+		}
+	}
+	return false
+}
+
+// addMember adds specified character to the group.
+func (g *Group) addMember(c *Character) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.members = append(g.members, c)
+	sort.Slice(g.members, func(i, j int) bool {
+		return g.members[i].ID()+g.members[i].Serial() <
+			g.members[j].ID()+g.members[j].Serial()
+	})
+	g.selectLeader()
+}
+
+// removeMember removes specified character from the group.
+func (g *Group) removeMember(c *Character) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for i, m := range g.members {
+		if m == c {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			break
+		}
+	}
+	if g.leader == c {
+		g.leader = nil
+	}
+	g.selectLeader()
+}
+
+// clear removes all members from the group.
+func (g *Group) clear() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.members = nil
+	g.leader = nil
+}
+
+// selectLeader selects group leader if the current leader
+// is not set or dead.
+// Member specified as leader in the group definition
+// is preferred, otherwise the live member with the highest
+// level is selected.
+func (g *Group) selectLeader() {
+	if g.leader != nil && g.leader.Live() {
+		return
+	}
+	g.leader = nil
+	for _, m := range g.members {
+		if !m.Live() {
+			continue
+		}
+		if m.ID() == g.LeaderID && (g.LeaderSerial == "" || m.Serial() == g.LeaderSerial) {
+			g.leader = m
+			return
+		}
+		if g.leader == nil || m.Level() > g.leader.Level() {
+			g.leader = m
+		}
+	}
+}
+
+// matches checks if specified character matches group
+// member characters or flags.
+func (g *Group) matches(c *Character) bool {
+	for _, id := range g.Characters {
+		if c.ID() == id {
+			return true
+		}
+	}
+	if c.ID() == g.LeaderID && (g.LeaderSerial == "" || c.Serial() == g.LeaderSerial) {
+		return true
+	}
+	for _, f := range c.Flags() {
+		for _, id := range g.Flags {
+			if f.ID() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formationSlot returns formation position for specified
+// follower.
+func (g *Group) formationSlot(follower *Character) (float64, float64, bool) {
+	leader := g.Leader()
+	if leader == nil {
+		return 0, 0, false
+	}
+	followers := g.Followers()
+	slot := -1
+	for i, f := range followers {
+		if f == follower {
+			slot = i
+			break
+		}
+	}
+	if slot < 0 {
+		return 0, 0, false
+	}
+	x, y := leader.Position()
+	switch g.Formation {
+	case Line:
+		dis := g.FormationDistance * float64(slot+1)
+		x -= dis * math.Cos(leader.Facing())
+		y -= dis * math.Sin(leader.Facing())
+	default:
+		angle := leader.Facing() + math.Pi + 2*math.Pi*float64(slot)/float64(len(followers))
+		x += g.FormationDistance * math.Cos(angle)
+		y += g.FormationDistance * math.Sin(angle)
+	}
+	return x, y, true
+}
+
+// updateGroups updates all game groups.
+// Handles leader death and shares hostile targets between
+// group members.
+func (ai *AI) updateGroups() {
+	for _, g := range ai.Game().Groups() {
+		leader := g.Leader()
+		if leader != nil && !leader.Live() {
+			ai.handleLeaderDeath(g, leader)
+			continue
+		}
+		// Group aggro.
+		var tar *Character
+		for _, m := range g.Members() {
+			if m.Live() && m.hasHostileTarget() {
+				tar = m
+				break
+			}
+		}
+		if tar == nil {
+			continue
+		}
+		// Only targets hostile for the member are shared, so members
+		// don't retarget objects they won't fight.
+		shared := tar.Targets()[0]
+		for _, m := range g.Members() {
+			if m.Live() && !m.hasHostileTarget() && m.Hostile(shared) {
+				m.SetTarget(shared)
+			}
+		}
+	}
+}
+
+// handleLeaderDeath handles death of the leader of specified group.
+func (ai *AI) handleLeaderDeath(g *Group, leader *Character) {
+	switch g.LeaderDeath {
+	case Scatter:
+		g.clear()
+	case Flee:
+		leaderX, leaderY := leader.Position()
+		for _, m := range g.Members() {
+			if !m.Live() {
+				continue
+			}
+			// Flee away from the enemy or the leader body.
+			fromX, fromY := leaderX, leaderY
+			if m.hasHostileTarget() {
+				fromX, fromY = m.Targets()[0].Position()
+				m.SetTarget(nil)
+			}
+			posX, posY := m.Position()
+			angle := math.Atan2(posY-fromY, posX-fromX)
+			m.Flee(posX+g.FleeDistance*math.Cos(angle),
+				posY+g.FleeDistance*math.Sin(angle))
+		}
+		g.clear()
+	default:
+		g.mutex.Lock()
+		g.selectLeader()
+		g.mutex.Unlock()
+	}
+}
+
+// keepFormation moves specified group follower to its
+// formation slot.
+func (ai *AI) keepFormation(npc *Character, g *Group) {
+	x, y, ok := g.formationSlot(npc)
+	if !ok {
+		return
+	}
+	destX, destY := npc.DestPoint()
+	if math.Hypot(destX-x, destY-y) <= formationTolerance {
+		return
+	}
+	npc.SetDestPoint(x, y)
+}
+
+// Flee makes the character flee to specified position.
+// Fleeing character doesn't look for targets until it reaches
+// the position or the flee time limit passes.
+func (c *Character) Flee(x, y float64) {
+	c.flee = &flee{x: x, y: y}
+	c.SetDestPoint(x, y)
+}
+
+// Fleeing checks if the character is fleeing.
+func (c *Character) Fleeing() bool {
+	return c.flee != nil
+}
+
+// updateFlee keeps fleeing character moving to its flee point
+// and stops fleeing after reaching the point or after the flee
+// time limit.
+// Returns true if the character is still fleeing.
+func (c *Character) updateFlee(delta int64) bool {
+	if c.flee == nil {
+		return false
+	}
+	c.flee.timer += delta
+	posX, posY := c.Position()
+	if c.flee.timer >= fleeTimeout || math.Hypot(c.flee.x-posX, c.flee.y-posY) <= fleeTolerance {
+		c.flee = nil
+		return false
+	}
+	if len(c.Targets()) > 0 {
+		c.SetTarget(nil)
+	}
+	destX, destY := c.DestPoint()
+	if destX != c.flee.x || destY != c.flee.y {
+		c.SetDestPoint(c.flee.x, c.flee.y)
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+/*
+ * group_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+)
+
+var groupText = `characters:wolf
+leader:wolf_alpha;0
+leader-death:flee
+formation:line
+`
+
+// TestUnmarshalGroup tests parsing group text.
+func TestUnmarshalGroup(t *testing.T) {
+	g, err := UnmarshalGroup("wolves", strings.NewReader(groupText))
+	if err != nil {
+		t.Fatalf("Unable to unmarshal group: %v", err)
+	}
+	if g.LeaderID != "wolf_alpha" || g.LeaderSerial != "0" {
+		t.Errorf("Invalid group leader: %s %s", g.LeaderID, g.LeaderSerial)
+	}
+	if g.LeaderDeath != Flee || g.Formation != Line {
+		t.Errorf("Invalid group settings: %s %s", g.LeaderDeath, g.Formation)
+	}
+	if g.FormationDistance != defFormationDistance {
+		t.Errorf("Invalid default formation distance: %f", g.FormationDistance)
+	}
+	_, err = UnmarshalGroup("wolves", strings.NewReader("formation:square"))
+	if err == nil {
+		t.Errorf("No error for invalid formation")
+	}
+}
+
+// TestLeaderDeathFlee tests keeping flee point after leader death.
+func TestLeaderDeathFlee(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	g := NewGroup("wolves")
+	g.Characters = []string{"char"}
+	g.LeaderID = "leader"
+	g.LeaderDeath = Flee
+	game.AddGroup(g)
+	leaderData := charData
+	leaderData.ID = "leader"
+	leader := NewCharacter(character.New(leaderData), game)
+	member := NewCharacter(character.New(charData), game)
+	game.AddCharacter(leader)
+	game.AddCharacter(member)
+	leader.SetPosition(10, 0)
+	leader.SetHealth(0)
+	ai := New(game)
+	ai.Update(16)
+	if !member.Fleeing() {
+		t.Fatalf("Member is not fleeing")
+	}
+	fleeX, fleeY := member.DestPoint()
+	if math.Hypot(fleeX+g.FleeDistance, fleeY) > 0.01 {
+		t.Fatalf("Invalid flee point: %f %f", fleeX, fleeY)
+	}
+	member.SetDestPoint(0, 0)
+	ai.Update(16)
+	if x, y := member.DestPoint(); x != fleeX || y != fleeY {
+		t.Errorf("Flee point not kept: %f %f", x, y)
+	}
+	ai.Update(fleeTimeout)
+	if member.Fleeing() {
+		t.Errorf("Member is still fleeing after flee time limit")
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * loot.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"math"
+
+	"github.com/isangeles/flame/effect"
+	"github.com/isangeles/flame/item"
+	"github.com/isangeles/flame/objects"
+
+	"github.com/isangeles/fire/request"
+)
+
+// Interface for objects with inventory.
+type Container interface {
+	effect.Target
+	Inventory() *item.Inventory
+}
+
+// loot moves specified NPC to the nearest dead character with
+// items matching the NPC profile loot filters, and takes these
+// items if the NPC is close enough.
+// Returns true if the NPC is busy with looting.
+func (ai *AI) loot(npc *Character) bool {
+	p := npc.Profile()
+	if p.LootRange <= 0 || ai.Game().Chapter() == nil {
+		return false
+	}
+	area := ai.Game().Chapter().ObjectArea(npc)
+	if area == nil {
+		return false
+	}
+	npcX, npcY := npc.Position()
+	var body Container
+	var items []item.Item
+	bodyDis := 0.0
+	for _, o := range area.NearObjects(npcX, npcY, p.LootRange) {
+		if o == npc.Character || targetLive(o) {
+			continue
+		}
+		if _, ok := o.(objects.Killable); !ok {
+			continue
+		}
+		c, ok := o.(Container)
+		if !ok {
+			continue
+		}
+		lootItems := npc.lootItems(c)
+		if len(lootItems) < 1 {
+			continue
+		}
+		x, y := c.Position()
+		dis := math.Hypot(x-npcX, y-npcY)
+		if body == nil || dis < bodyDis {
+			body, items, bodyDis = c, lootItems, dis
+		}
+	}
+	if body == nil {
+		return false
+	}
+	if bodyDis > p.LootPickupRange {
+		x, y := body.Position()
+		npc.MoveCloseTo(x, y, p.LootPickupRange/2)
+		return true
+	}
+	npc.TakeItems(body, items...)
+	return false
+}
+
+// lootItems returns items from specified container matching
+// the character profile loot filters.
+func (c *Character) lootItems(con Container) (items []item.Item) {
+	p := c.Profile()
+	for _, invItem := range con.Inventory().Items() {
+		it := invItem.Item
+		if len(p.LootItems) > 0 && !contains(p.LootItems, it.ID()) {
+			continue
+		}
+		if len(p.LootCategories) > 0 && !contains(p.LootCategories, ItemCategory(it)) {
+			continue
+		}
+		items = append(items, it)
+	}
+	return
+}
+
+// TakeItems transfers specified items from specified container
+// to the character inventory.
+func (c *Character) TakeItems(con Container, items ...item.Item) {
+	if c.game.Server() == nil {
+		for _, it := range items {
+			con.Inventory().RemoveItem(it)
+			err := c.Inventory().AddItem(it)
+			if err != nil {
+				c.logger().Error("unable to add item", "item-id", it.ID(), "error", err)
+			}
+		}
+		return
+	}
+	transferReq := request.Transfer{
+		ObjectFromID:     con.ID(),
+		ObjectFromSerial: con.Serial(),
+		ObjectToID:       c.ID(),
+		ObjectToSerial:   c.Serial(),
+		Items:            make(map[string][]string),
+	}
+	for _, it := range items {
+		transferReq.Items[it.ID()] = append(transferReq.Items[it.ID()], it.Serial())
+	}
+	req := request.Request{Transfer: []request.Transfer{transferReq}}
+	err := c.game.Server().Send(req)
+	if err != nil {
+		c.logger().Error("unable to send request", "request", "transfer", "error", err)
+	}
+}
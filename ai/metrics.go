@@ -0,0 +1,246 @@
+/*
+ * metrics.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Upper bounds of tick duration histogram buckets in seconds.
+var tickBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25}
+
+// Struct for AI metrics.
+// All methods are safe to call on nil metrics, so metrics
+// collection is disabled if metrics are not set.
+type Metrics struct {
+	client        string
+	mutex         sync.Mutex
+	npcs          int
+	combatNPCs    int
+	requests      map[string]int64
+	responses     int64
+	sendErrors    int64
+	reconnects    int64
+	tradeAccepted int64
+	tradeRejected int64
+	tickCounts    []int64
+	tickCount     int64
+	tickSum       float64
+}
+
+// NewMetrics creates new metrics for the AI client with specified
+// name, client name is added as label to all metrics if not empty.
+func NewMetrics(client string) *Metrics {
+	m := Metrics{
+		client:     client,
+		requests:   make(map[string]int64),
+		tickCounts: make([]int64, len(tickBuckets)),
+	}
+	return &m
+}
+
+// MetricsHandler returns HTTP handler that serves specified metrics
+// in Prometheus text format.
+func MetricsHandler(metrics ...*Metrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WriteMetrics(w, metrics...)
+	})
+}
+
+// WriteMetrics writes specified metrics to specified writer in
+// Prometheus text format.
+func WriteMetrics(w io.Writer, metrics ...*Metrics) error {
+	buf := new(bytes.Buffer)
+	family := func(name, kind, help string, samples func(m *Metrics)) {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, m := range metrics {
+			m.mutex.Lock()
+			samples(m)
+			m.mutex.Unlock()
+		}
+	}
+	family("ignite_npcs", "gauge", "Number of NPCs controlled by the AI.", func(m *Metrics) {
+		fmt.Fprintf(buf, "ignite_npcs%s %d\n", m.labels(), m.npcs)
+	})
+	family("ignite_npcs_in_combat", "gauge", "Number of controlled NPCs in combat.", func(m *Metrics) {
+		fmt.Fprintf(buf, "ignite_npcs_in_combat%s %d\n", m.labels(), m.combatNPCs)
+	})
+	family("ignite_requests_sent_total", "counter", "Number of requests sent to the server by type.", func(m *Metrics) {
+		types := make([]string, 0, len(m.requests))
+		for t := range m.requests {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			fmt.Fprintf(buf, "ignite_requests_sent_total%s %d\n", m.labels("type", t), m.requests[t])
+		}
+	})
+	family("ignite_responses_received_total", "counter", "Number of responses received from the server.", func(m *Metrics) {
+		fmt.Fprintf(buf, "ignite_responses_received_total%s %d\n", m.labels(), m.responses)
+	})
+	family("ignite_send_errors_total", "counter", "Number of failed requests.", func(m *Metrics) {
+		fmt.Fprintf(buf, "ignite_send_errors_total%s %d\n", m.labels(), m.sendErrors)
+	})
+	family("ignite_reconnects_total", "counter", "Number of attempts to restore server connection.", func(m *Metrics) {
+		fmt.Fprintf(buf, "ignite_reconnects_total%s %d\n", m.labels(), m.reconnects)
+	})
+	family("ignite_trades_total", "counter", "Number of trades evaluated by the AI by result.", func(m *Metrics) {
+		fmt.Fprintf(buf, "ignite_trades_total%s %d\n", m.labels("result", "accepted"), m.tradeAccepted)
+		fmt.Fprintf(buf, "ignite_trades_total%s %d\n", m.labels("result", "rejected"), m.tradeRejected)
+	})
+	family("ignite_tick_duration_seconds", "histogram", "Duration of the AI updates.", func(m *Metrics) {
+		count := int64(0)
+		for i, b := range tickBuckets {
+			count += m.tickCounts[i]
+			le := strconv.FormatFloat(b, 'f', -1, 64)
+			fmt.Fprintf(buf, "ignite_tick_duration_seconds_bucket%s %d\n", m.labels("le", le), count)
+		}
+		fmt.Fprintf(buf, "ignite_tick_duration_seconds_bucket%s %d\n", m.labels("le", "+Inf"), m.tickCount)
+		fmt.Fprintf(buf, "ignite_tick_duration_seconds_sum%s %g\n", m.labels(), m.tickSum)
+		fmt.Fprintf(buf, "ignite_tick_duration_seconds_count%s %d\n", m.labels(), m.tickCount)
+	})
+	_, err := buf.WriteTo(w)
+	if err != nil {
+		return fmt.Errorf("Unable to write metrics: %v", err)
+	}
+	return nil
+}
+
+// AddReconnect increments number of attempts to restore server
+// connection.
+func (m *Metrics) AddReconnect() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.reconnects++
+}
+
+// addRequest increments number of sent requests of specified
+// types.
+func (m *Metrics) addRequest(types ...string) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for _, t := range types {
+		m.requests[t]++
+	}
+}
+
+// addSendError increments number of failed requests.
+func (m *Metrics) addSendError() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sendErrors++
+}
+
+// addResponse increments number of received responses.
+func (m *Metrics) addResponse() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.responses++
+}
+
+// addTrade increments number of accepted or rejected trades.
+func (m *Metrics) addTrade(accepted bool) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if accepted {
+		m.tradeAccepted++
+	} else {
+		m.tradeRejected++
+	}
+}
+
+// addTick adds AI update with specified duration and numbers
+// of controlled NPCs and NPCs in combat.
+func (m *Metrics) addTick(d time.Duration, npcs, combatNPCs int) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.npcs, m.combatNPCs = npcs, combatNPCs
+	m.tickCount++
+	m.tickSum += d.Seconds()
+	for i, b := range tickBuckets {
+		if d.Seconds() <= b {
+			m.tickCounts[i]++
+			break
+		}
+	}
+}
+
+// updateMetrics adds AI update with specified duration to the game
+// metrics, if set.
+func (ai *AI) updateMetrics(d time.Duration) {
+	if ai.Game().Metrics() == nil {
+		return
+	}
+	npcs, combatNPCs := 0, 0
+	for _, npc := range ai.Game().Characters() {
+		npcs++
+		if npc.Fighting() {
+			combatNPCs++
+		}
+	}
+	ai.Game().Metrics().addTick(d, npcs, combatNPCs)
+}
+
+// labels returns labels text for metric sample with client name
+// label and specified label names and values.
+func (m *Metrics) labels(nameValues ...string) string {
+	if len(m.client) > 0 {
+		nameValues = append([]string{"client", m.client}, nameValues...)
+	}
+	if len(nameValues) < 2 {
+		return ""
+	}
+	labels := make([]string, 0)
+	for i := 0; i+1 < len(nameValues); i += 2 {
+		labels = append(labels, fmt.Sprintf("%s=%q", nameValues[i], nameValues[i+1]))
+	}
+	return "{" + strings.Join(labels, ",") + "}"
+}
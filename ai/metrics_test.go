@@ -0,0 +1,79 @@
+/*
+ * metrics_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/data/res"
+)
+
+// TestWriteMetrics tests writing metrics in Prometheus text format.
+func TestWriteMetrics(t *testing.T) {
+	m := NewMetrics("town")
+	m.addRequest("move", "chat")
+	m.addRequest("move")
+	m.addTrade(false)
+	m.addTick(2*time.Millisecond, 3, 1)
+	var nilMetrics *Metrics
+	nilMetrics.addRequest("move")
+	buf := new(bytes.Buffer)
+	err := WriteMetrics(buf, m, NewMetrics(""))
+	if err != nil {
+		t.Fatalf("Unable to write metrics: %v", err)
+	}
+	for _, l := range []string{
+		"# TYPE ignite_npcs gauge",
+		`ignite_npcs{client="town"} 3`,
+		`ignite_npcs_in_combat{client="town"} 1`,
+		`ignite_requests_sent_total{client="town",type="move"} 2`,
+		`ignite_requests_sent_total{client="town",type="chat"} 1`,
+		`ignite_trades_total{client="town",result="rejected"} 1`,
+		`ignite_tick_duration_seconds_bucket{client="town",le="0.001"} 0`,
+		`ignite_tick_duration_seconds_bucket{client="town",le="0.0025"} 1`,
+		`ignite_tick_duration_seconds_count{client="town"} 1`,
+		"ignite_npcs 0",
+	} {
+		if !strings.Contains(buf.String(), l+"\n") {
+			t.Errorf("Metric line not found: %s", l)
+		}
+	}
+}
+
+// TestUpdateMetrics tests collecting metrics on AI update.
+func TestUpdateMetrics(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	game.SetMetrics(NewMetrics(""))
+	ai := New(game)
+	ai.Update(16)
+	ai.Update(16)
+	if game.Metrics().tickCount != 2 {
+		t.Errorf("Invalid number of updates: %d != 2", game.Metrics().tickCount)
+	}
+}
@@ -0,0 +1,274 @@
+/*
+ * profile.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/isangeles/flame/data/text"
+)
+
+const (
+	ProfileFileExt     = ".profile"
+	DefaultProfileID   = "default"
+	defLootPickupRange = 30.0
+)
+
+// Struct for NPC behavior profile.
+type Profile struct {
+	ID         string
+	Characters []string
+	// Aggro radius, if zero the NPC sight range is used.
+	AggroRange float64
+	// Aggro radius reduction for each level of target above
+	// the NPC level(0.1 = 10% per level).
+	AggroLevelFactor float64
+	// Aggro arc in degrees in front of the NPC, if zero or
+	// above 360 targets are noticed all around the NPC.
+	AggroArc float64
+	// IDs of target effects that make target unnoticeable.
+	StealthEffects []string
+	// Trade policy for merchants.
+	Trade *MerchantPolicy
+	// Send chat messages on trade rejection.
+	TradeRejectChat bool
+	// Suggest counter-offer on rejection of the trade.
+	TradeCounterOffer bool
+	// Quantities of items restocked by merchants.
+	Restock map[string]int
+	// Restock frequency in milliseconds.
+	RestockFreq int64
+	// Price change for items in low stock or overstocked,
+	// relative to the restock quantity.
+	PriceElasticity float64
+	// Daily routines.
+	Routines []Routine
+	// Range for looking for dead characters to loot,
+	// looting is disabled if zero.
+	LootRange float64
+	// Maximal distance for taking items from dead characters.
+	LootPickupRange float64
+	// IDs of looted items, all items if empty.
+	LootItems []string
+	// Categories of looted items, all categories if empty.
+	LootCategories []string
+	// ID and serial of object escorted by the NPC.
+	EscortLeaderID, EscortLeaderSerial string
+	// Escort distances and timeout.
+	EscortDistance, EscortGiveUpDistance float64
+	EscortTimeout                        int64
+	// ID of NPC faction.
+	Faction string
+	// IDs of enemy factions of the NPC faction.
+	FactionEnemies []string
+	// Standing with the NPC faction below which players are
+	// hostile for the NPC.
+	HostileStanding int
+	// Standing with the NPC faction above which players are
+	// friendly for the NPC.
+	FriendlyStanding int
+}
+
+// defaultProfile is used for characters without
+// assigned profile.
+var defaultProfile = &Profile{
+	ID:                   DefaultProfileID,
+	Trade:                DefaultMerchantPolicy(),
+	TradeRejectChat:      true,
+	LootPickupRange:      defLootPickupRange,
+	EscortDistance:       defEscortDistance,
+	EscortGiveUpDistance: defEscortGiveUpDistance,
+	HostileStanding:      defHostileStanding,
+	FriendlyStanding:     defFriendlyStanding,
+}
+
+// UnmarshalProfile parses profile from specified reader.
+func UnmarshalProfile(id string, r io.Reader) (*Profile, error) {
+	conf, err := text.UnmarshalConfig(r)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal profile config: %v", err)
+	}
+	p := Profile{
+		ID:                   id,
+		Trade:                DefaultMerchantPolicy(),
+		TradeRejectChat:      true,
+		LootPickupRange:      defLootPickupRange,
+		EscortDistance:       defEscortDistance,
+		EscortGiveUpDistance: defEscortGiveUpDistance,
+		HostileStanding:      defHostileStanding,
+		FriendlyStanding:     defFriendlyStanding,
+	}
+	p.Characters = conf["characters"]
+	p.StealthEffects = conf["stealth-effects"]
+	p.Trade.AllowItems = conf["trade-allow-items"]
+	p.Trade.RefuseItems = conf["trade-refuse-items"]
+	p.Trade.AllowCategories = conf["trade-allow-categories"]
+	p.Trade.RefuseCategories = conf["trade-refuse-categories"]
+	if len(conf["trade-reject-chat"]) > 0 {
+		p.TradeRejectChat = conf["trade-reject-chat"][0] == "true"
+	}
+	if len(conf["trade-counter-offer"]) > 0 {
+		p.TradeCounterOffer = conf["trade-counter-offer"][0] == "true"
+	}
+	if len(conf["escort"]) > 1 {
+		p.EscortLeaderID = conf["escort"][0]
+		p.EscortLeaderSerial = conf["escort"][1]
+	}
+	if len(conf["escort-timeout"]) > 0 {
+		p.EscortTimeout, err = strconv.ParseInt(conf["escort-timeout"][0], 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid escort-timeout value: %v", err)
+		}
+	}
+	if len(conf["faction"]) > 0 {
+		p.Faction = conf["faction"][0]
+	}
+	p.FactionEnemies = conf["faction-enemies"]
+	p.LootItems = conf["loot-items"]
+	p.LootCategories = conf["loot-categories"]
+	p.Routines, err = parseRoutines(conf["routine"])
+	if err != nil {
+		return nil, err
+	}
+	p.Restock = make(map[string]int)
+	for i := 0; i+1 < len(conf["restock"]); i += 2 {
+		quantity, err := strconv.Atoi(conf["restock"][i+1])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid restock value: %v", err)
+		}
+		p.Restock[conf["restock"][i]] = quantity
+	}
+	if len(conf["restock-freq"]) > 0 {
+		p.RestockFreq, err = strconv.ParseInt(conf["restock-freq"][0], 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid restock-freq value: %v", err)
+		}
+	}
+	floats := map[string]*float64{
+		"aggro-range":             &p.AggroRange,
+		"aggro-level-factor":      &p.AggroLevelFactor,
+		"aggro-arc":               &p.AggroArc,
+		"trade-buy-markup":        &p.Trade.BuyMarkup,
+		"trade-sell-markup":       &p.Trade.SellMarkup,
+		"trade-friendly-discount": &p.Trade.FriendlyDiscount,
+		"price-elasticity":        &p.PriceElasticity,
+		"loot-range":              &p.LootRange,
+		"loot-pickup-range":       &p.LootPickupRange,
+		"escort-distance":         &p.EscortDistance,
+		"escort-give-up-distance": &p.EscortGiveUpDistance,
+	}
+	for key, value := range floats {
+		err := confFloat(conf, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ints := map[string]*int{
+		"trade-budget":      &p.Trade.Budget,
+		"trade-buy-limit":   &p.Trade.BuyLimit,
+		"hostile-standing":  &p.HostileStanding,
+		"friendly-standing": &p.FriendlyStanding,
+	}
+	for key, value := range ints {
+		err := confInt(conf, key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+// escort creates escort task for object with specified ID
+// and serial with profile escort values.
+func (p *Profile) escort(leaderID, leaderSerial string) *Escort {
+	e := NewEscort(leaderID, leaderSerial)
+	e.Distance = p.EscortDistance
+	e.GiveUpDistance = p.EscortGiveUpDistance
+	e.Timeout = p.EscortTimeout
+	return e
+}
+
+// ImportProfilesDir imports all profiles from files with profile
+// extension in directory with specified path.
+// Profile ID is a file name without extension.
+func ImportProfilesDir(path string) ([]*Profile, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read dir: %v", err)
+	}
+	profiles := make([]*Profile, 0)
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != ProfileFileExt {
+			continue
+		}
+		file, err := os.Open(filepath.Join(path, f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to open profile file: %v", err)
+		}
+		id := strings.TrimSuffix(f.Name(), ProfileFileExt)
+		p, err := UnmarshalProfile(id, file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("Unable to unmarshal profile: %s: %v",
+				f.Name(), err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+// confFloat parses first value for specified key from specified
+// config as float and sets it to specified value.
+// Value remains unchanged if config has no such key.
+func confFloat(conf map[string][]string, key string, value *float64) error {
+	if len(conf[key]) < 1 {
+		return nil
+	}
+	v, err := strconv.ParseFloat(conf[key][0], 64)
+	if err != nil {
+		return fmt.Errorf("Invalid %s value: %v", key, err)
+	}
+	*value = v
+	return nil
+}
+
+// confInt parses first value for specified key from specified
+// config as int and sets it to specified value.
+// Value remains unchanged if config has no such key.
+func confInt(conf map[string][]string, key string, value *int) error {
+	if len(conf[key]) < 1 {
+		return nil
+	}
+	v, err := strconv.Atoi(conf[key][0])
+	if err != nil {
+		return fmt.Errorf("Invalid %s value: %v", key, err)
+	}
+	*value = v
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+ * profile_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+)
+
+var profileText = `characters:char
+aggro-range:100
+aggro-level-factor:0.1
+aggro-arc:90
+`
+
+// TestUnmarshalProfile tests parsing profile text.
+func TestUnmarshalProfile(t *testing.T) {
+	p, err := UnmarshalProfile("test", strings.NewReader(profileText))
+	if err != nil {
+		t.Fatalf("Unable to unmarshal profile: %v", err)
+	}
+	if len(p.Characters) != 1 || p.Characters[0] != "char" {
+		t.Errorf("Invalid profile characters: %v", p.Characters)
+	}
+	if p.AggroRange != 100 || p.AggroLevelFactor != 0.1 || p.AggroArc != 90 {
+		t.Errorf("Invalid profile aggro values: %v %v %v", p.AggroRange,
+			p.AggroLevelFactor, p.AggroArc)
+	}
+}
+
+// TestAggroRange tests scaling aggro range with target level.
+func TestAggroRange(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	p, err := UnmarshalProfile("test", strings.NewReader(profileText))
+	if err != nil {
+		t.Fatalf("Unable to unmarshal profile: %v", err)
+	}
+	game.AddProfile(p)
+	char := NewCharacter(character.New(charData), game)
+	tarData := charData
+	tarData.Level = charData.Level + 2
+	tar := character.New(tarData)
+	if r := char.AggroRange(nil); r != 100 {
+		t.Errorf("Invalid base aggro range: %f", r)
+	}
+	if r := char.AggroRange(tar); r != 80 {
+		t.Errorf("Invalid aggro range for higher level target: %f", r)
+	}
+}
@@ -0,0 +1,237 @@
+/*
+ * reputation.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/effect"
+	"github.com/isangeles/flame/serial"
+)
+
+const (
+	defHostileStanding  = -100
+	defFriendlyStanding = 100
+)
+
+// Struct for standing of players with NPC factions.
+type Reputation struct {
+	mutex sync.RWMutex
+	// Standing for faction ID and player ID with serial.
+	standing map[string]map[string]int
+	changed  bool
+}
+
+// NewReputation creates new empty reputation.
+func NewReputation() *Reputation {
+	r := Reputation{standing: make(map[string]map[string]int)}
+	return &r
+}
+
+// LoadReputation loads reputation from the JSON file with
+// specified path.
+// Returns empty reputation if the file doesn't exist.
+func LoadReputation(path string) (*Reputation, error) {
+	r := NewReputation()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read reputation file: %v", err)
+	}
+	err = json.Unmarshal(data, &r.standing)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal reputation: %v", err)
+	}
+	return r, nil
+}
+
+// Save saves reputation to the JSON file with specified path.
+func (r *Reputation) Save(path string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	data, err := json.MarshalIndent(r.standing, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to marshal reputation: %v", err)
+	}
+	err = os.WriteFile(path, data, 0640)
+	if err != nil {
+		return fmt.Errorf("Unable to write reputation file: %v", err)
+	}
+	r.changed = false
+	return nil
+}
+
+// Changed checks if reputation was changed since last save.
+func (r *Reputation) Changed() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.changed
+}
+
+// Standing returns standing of specified object with faction
+// with specified ID.
+func (r *Reputation) Standing(faction string, ob serial.Serialer) int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.standing[faction][ob.ID()+ob.Serial()]
+}
+
+// Change changes standing of specified object with faction with
+// specified ID by specified value.
+func (r *Reputation) Change(faction string, ob serial.Serialer, value int) {
+	if len(faction) < 1 || value == 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.standing[faction] == nil {
+		r.standing[faction] = make(map[string]int)
+	}
+	r.standing[faction][ob.ID()+ob.Serial()] += value
+	r.changed = true
+}
+
+// Hostile checks if specified object is hostile for the character,
+// because of the character attitude, object standing with the
+// character faction or attack on the object escorted by the
+// character.
+func (c *Character) Hostile(ob serial.Serialer) bool {
+	if c.AttitudeFor(ob) == character.Hostile {
+		return true
+	}
+	if e := c.Escort(); e != nil && e.attacker != nil && e.attacker.ID() == ob.ID() &&
+		e.attacker.Serial() == ob.Serial() {
+		return true
+	}
+	p := c.Profile()
+	rep := c.game.Reputation()
+	if len(p.Faction) < 1 || rep == nil || c.game.character(ob) != nil {
+		return false
+	}
+	return rep.Standing(p.Faction, ob) <= p.HostileStanding
+}
+
+// Friendly checks if specified object is friendly for the character,
+// because of the character attitude or object standing with the
+// character faction.
+func (c *Character) Friendly(ob serial.Serialer) bool {
+	if c.AttitudeFor(ob) == character.Friendly {
+		return true
+	}
+	p := c.Profile()
+	rep := c.game.Reputation()
+	if len(p.Faction) < 1 || rep == nil {
+		return false
+	}
+	return rep.Standing(p.Faction, ob) >= p.FriendlyStanding
+}
+
+// Struct for attack on AI character.
+type attack struct {
+	attacker effect.Target
+	victim   *Character
+}
+
+// updateReputation updates reputation of objects fighting with AI
+// characters and saves reputation if changed.
+// Attack on faction member changes the attacker standing with the
+// victim faction, and killing a faction member changes the attacker
+// standing with enemy factions of the victim faction.
+func (ai *AI) updateReputation(delta int64) {
+	rep := ai.Game().Reputation()
+	if rep == nil {
+		return
+	}
+	attacks := make(map[string]attack)
+	for _, npc := range ai.Game().Characters() {
+		for _, attacker := range ai.attackers(npc) {
+			key := attacker.ID() + attacker.Serial() + npc.ID() + npc.Serial()
+			attacks[key] = attack{attacker, npc}
+			if _, ok := ai.attacks[key]; !ok {
+				rep.Change(npc.Profile().Faction, attacker, ai.Game().Config().ReputationAttack)
+			}
+		}
+	}
+	// Check finished attacks.
+	for key, a := range ai.attacks {
+		if _, ok := attacks[key]; ok || a.victim.Live() {
+			continue
+		}
+		for _, p := range ai.Game().Profiles() {
+			if contains(p.FactionEnemies, a.victim.Profile().Faction) {
+				rep.Change(p.Faction, a.attacker, ai.Game().Config().ReputationKill)
+			}
+		}
+	}
+	ai.attacks = attacks
+	// Save.
+	ai.reputationTimer += delta
+	if ai.reputationTimer < ai.Game().Config().ReputationSaveFreq || len(ai.reputationPath) < 1 {
+		return
+	}
+	ai.reputationTimer = 0
+	if !rep.Changed() {
+		return
+	}
+	err := rep.Save(ai.reputationPath)
+	if err != nil {
+		ai.Logger().Error("unable to save reputation", "path", ai.reputationPath, "error", err)
+	}
+}
+
+// attackers returns all objects not controlled by the AI which
+// are fighting with specified NPC.
+func (ai *AI) attackers(npc *Character) (attackers []effect.Target) {
+	if ai.Game().Chapter() == nil {
+		return
+	}
+	area := ai.Game().Chapter().ObjectArea(npc)
+	if area == nil {
+		return
+	}
+	npcX, npcY := npc.Position()
+	for _, o := range area.NearObjects(npcX, npcY, npc.SightRange()) {
+		if ai.Game().character(o) != nil {
+			continue
+		}
+		fighter, ok := o.(interface {
+			Fighting() bool
+			Targets() []effect.Target
+		})
+		if !ok || !fighter.Fighting() || len(fighter.Targets()) < 1 {
+			continue
+		}
+		tar := fighter.Targets()[0]
+		if tar.ID() == npc.ID() && tar.Serial() == npc.Serial() {
+			attackers = append(attackers, o)
+		}
+	}
+	return
+}
@@ -0,0 +1,54 @@
+/*
+ * reputation_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/isangeles/flame/character"
+)
+
+// TestReputationSave tests saving and loading reputation.
+func TestReputationSave(t *testing.T) {
+	player := character.New(charData)
+	rep := NewReputation()
+	rep.Change("town", player, -50)
+	rep.Change("town", player, -60)
+	if s := rep.Standing("town", player); s != -110 {
+		t.Fatalf("Invalid standing: %d", s)
+	}
+	path := filepath.Join(t.TempDir(), "reputation.json")
+	err := rep.Save(path)
+	if err != nil {
+		t.Fatalf("Unable to save reputation: %v", err)
+	}
+	rep, err = LoadReputation(path)
+	if err != nil {
+		t.Fatalf("Unable to load reputation: %v", err)
+	}
+	if s := rep.Standing("town", player); s != -110 {
+		t.Errorf("Invalid standing after load: %d", s)
+	}
+}
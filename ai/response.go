@@ -25,8 +25,6 @@ package ai
 
 import (
 	"fmt"
-	"log"
-	"sync"
 
 	"github.com/isangeles/flame/character"
 	"github.com/isangeles/flame/data/res"
@@ -35,8 +33,6 @@ import (
 	"github.com/isangeles/fire/response"
 )
 
-var handleCharRespMutex sync.Mutex
-
 // handleResponse handles specified response from Fire server.
 func (g *Game) handleResponse(resp response.Response) {
 	if !resp.Logon && g.onLoginFunc != nil {
@@ -48,25 +44,26 @@ func (g *Game) handleResponse(resp response.Response) {
 	for _, r := range resp.Trade {
 		err := g.handleTradeResponse(r)
 		if err != nil {
-			log.Printf("Game server: unable to handle trade response: %v",
-				err)
+			g.Logger().Error("unable to handle trade response", "error", err)
 		}
 	}
 	for _, r := range resp.Error {
-		log.Printf("Game server error: %s", r)
+		g.Logger().Warn("server error response", "message", r)
 	}
 }
 
 // handleUpdateRespone handles update response.
 func (g *Game) handleUpdateResponse(resp response.Update) {
-	res.Clear()
+	if g.clearRes {
+		res.Clear()
+	}
 	g.Apply(resp.Module)
 }
 
 // handleCharacterResponse handles character response from the server.
 func (g *Game) handleCharacterResponse(resp []response.Character) {
-	handleCharRespMutex.Lock()
-	defer handleCharRespMutex.Unlock()
+	g.respMutex.Lock()
+	defer g.respMutex.Unlock()
 	// Add new characters.
 	for _, charResp := range resp {
 		if v, ok := g.characters.Load(charResp.ID + charResp.Serial); ok && v != nil {
@@ -74,8 +71,8 @@ func (g *Game) handleCharacterResponse(resp []response.Character) {
 		}
 		char := g.Chapter().Character(charResp.ID, charResp.Serial)
 		if char == nil {
-			log.Printf("Game server: handle character response: unable to find character in module: %s %s",
-				charResp.ID, charResp.Serial)
+			g.Logger().Warn("unable to find character in module", "char-id", charResp.ID,
+				"char-serial", charResp.Serial)
 			continue
 		}
 		g.AddCharacter(NewCharacter(char, g))
@@ -93,16 +90,23 @@ outer:
 }
 
 // handleTradeResponse handles trade response from the server.
+// Trade is evaluated with the seller trade policy and accepted
+// if the policy allows it, otherwise the seller informs the buyer
+// about rejection.
+// Trades are handled one at a time, so the seller trade budget
+// is not exceeded by concurrent trades.
 func (g *Game) handleTradeResponse(resp response.Trade) error {
+	g.respMutex.Lock()
+	defer g.respMutex.Unlock()
 	// Find seller & buyer.
 	object := g.Object(resp.SellerID, resp.SellerSerial)
 	if object == nil {
 		return fmt.Errorf("Seller not found: %s %s", resp.SellerID,
 			resp.SellerSerial)
 	}
-	seller, ok := object.(*character.Character)
-	if !ok {
-		return fmt.Errorf("Seller is not a character: %s %s", resp.SellerID,
+	seller := g.character(object)
+	if seller == nil {
+		return fmt.Errorf("Seller is not controlled by the AI: %s %s", resp.SellerID,
 			resp.SellerSerial)
 	}
 	object = g.Object(resp.BuyerID, resp.BuyerSerial)
@@ -116,25 +120,37 @@ func (g *Game) handleTradeResponse(resp response.Trade) error {
 			resp.BuyerSerial)
 	}
 	// Validate trade.
-	buyValue := 0
+	trade := Trade{
+		ID:     resp.ID,
+		Seller: seller,
+		Buyer:  buyer,
+	}
 	for id, serials := range resp.ItemsBuy {
 		for _, serial := range serials {
 			it := seller.Inventory().Item(id, serial)
 			if it != nil {
-				buyValue += it.Value()
+				trade.ItemsBuy = append(trade.ItemsBuy, it)
 			}
 		}
 	}
-	sellValue := 0
 	for id, serials := range resp.ItemsSell {
 		for _, serial := range serials {
 			it := buyer.Inventory().Item(id, serial)
 			if it != nil {
-				sellValue += it.Value()
+				trade.ItemsSell = append(trade.ItemsSell, it)
 			}
 		}
 	}
-	if sellValue < buyValue {
+	result := seller.TradePolicy().Evaluate(&trade)
+	if g.TradeLog() != nil {
+		err := g.TradeLog().Add(&trade, result)
+		if err != nil {
+			g.Logger().Error("unable to add trade log entry", "error", err)
+		}
+	}
+	g.Metrics().addTrade(result.Accept)
+	if !result.Accept {
+		seller.RejectTrade(&trade, result)
 		return nil
 	}
 	// Send accept request.
@@ -143,5 +159,9 @@ func (g *Game) handleTradeResponse(resp response.Trade) error {
 	if err != nil {
 		return fmt.Errorf("Unable to send accept request: %v", err)
 	}
+	seller.tradeAccepted(&trade)
+	if g.Reputation() != nil {
+		g.Reputation().Change(seller.Profile().Faction, buyer, g.Config().ReputationTrade)
+	}
 	return nil
 }
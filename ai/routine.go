@@ -0,0 +1,112 @@
+/*
+ * routine.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Struct for NPC daily routine.
+type Routine struct {
+	// Start and end hour of the routine, routine
+	// continues through midnight if start is after end.
+	Start, End float64
+	// Position of the NPC during the routine.
+	X, Y float64
+}
+
+// Active checks if the routine is active at specified time.
+func (r Routine) Active(t time.Time) bool {
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+	if r.Start <= r.End {
+		return hour >= r.Start && hour < r.End
+	}
+	return hour >= r.Start || hour < r.End
+}
+
+// parseRoutines parses routines from specified config values.
+// Each routine is defined by four values: start hour, end hour
+// and XY position.
+func parseRoutines(values []string) ([]Routine, error) {
+	if len(values)%4 != 0 {
+		return nil, fmt.Errorf("Invalid number of routine values: %d",
+			len(values))
+	}
+	routines := make([]Routine, 0)
+	for i := 0; i < len(values); i += 4 {
+		v := make([]float64, 4)
+		for j := range v {
+			f, err := strconv.ParseFloat(values[i+j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid routine value: %v", err)
+			}
+			v[j] = f
+		}
+		r := Routine{Start: v[0], End: v[1], X: v[2], Y: v[3]}
+		routines = append(routines, r)
+	}
+	return routines, nil
+}
+
+// Routine returns routine of the character active at the
+// current time of the character area, or nil if there is
+// no active routine.
+func (c *Character) Routine() *Routine {
+	routines := c.Profile().Routines
+	if len(routines) < 1 || c.game.Chapter() == nil {
+		return nil
+	}
+	area := c.game.Chapter().ObjectArea(c)
+	if area == nil {
+		return nil
+	}
+	for i, r := range routines {
+		if r.Active(area.Time()) {
+			return &routines[i]
+		}
+	}
+	return nil
+}
+
+// Anchor returns position the character returns to when idle.
+// This is the position of the active character routine or
+// character default position if there is no active routine.
+func (c *Character) Anchor() (float64, float64) {
+	r := c.Routine()
+	if r == nil {
+		return c.DefaultPosition()
+	}
+	return r.X, r.Y
+}
+
+// AnchorDistance returns distance from the character anchor
+// position.
+func (c *Character) AnchorDistance() float64 {
+	posX, posY := c.Position()
+	anchorX, anchorY := c.Anchor()
+	return math.Hypot(posX-anchorX, posY-anchorY)
+}
@@ -0,0 +1,45 @@
+/*
+ * routine_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRoutineActive tests checking if routine is active.
+func TestRoutineActive(t *testing.T) {
+	routines, err := parseRoutines([]string{"8", "18", "0", "0", "23", "8", "10", "10"})
+	if err != nil {
+		t.Fatalf("Unable to parse routines: %v", err)
+	}
+	noon := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	midnight := time.Date(2026, 1, 1, 0, 30, 0, 0, time.UTC)
+	if !routines[0].Active(noon) || routines[0].Active(midnight) {
+		t.Errorf("Invalid day routine activity")
+	}
+	if routines[1].Active(noon) || !routines[1].Active(midnight) {
+		t.Errorf("Invalid night routine activity")
+	}
+}
@@ -24,8 +24,12 @@
 package ai
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -38,25 +42,59 @@ type Server struct {
 	closed     bool
 	conn       *websocket.Conn
 	onResponse func(r response.Response)
+	logger     *slog.Logger
+	metrics    *Metrics
+	writeMutex sync.Mutex
+}
+
+// Struct for server dialing options.
+type DialOptions struct {
+	// TLS switches between ws and wss protocols.
+	TLS bool
+	// Logger for server connection messages, default logger
+	// is used if nil.
+	Logger *slog.Logger
+	// Metrics for server requests and responses, metrics are
+	// not collected if nil.
+	Metrics *Metrics
+	// Function triggered on server response.
+	OnResponse func(r response.Response)
 }
 
 // NewServer creates new server connection struct with connection
 // to the server with specified host and port number.
 // TLS switches between ws and wss protocols.
 func NewServer(host, port string, tls bool) (*Server, error) {
-	s := new(Server)
+	return DialContext(context.Background(), host, port, DialOptions{TLS: tls})
+}
+
+// DialContext creates new server connection struct with connection
+// to the server with specified host and port number and specified
+// options.
+// Options are set before the server starts handling responses.
+// Dialing is aborted if specified context is done before connection
+// is established.
+func DialContext(ctx context.Context, host, port string, opts DialOptions) (*Server, error) {
+	s := Server{
+		metrics:    opts.Metrics,
+		onResponse: opts.OnResponse,
+	}
 	protocol := "ws"
-	if tls {
+	if opts.TLS {
 		protocol = "wss"
 	}
 	url := fmt.Sprintf("%s://%s:%s/", protocol, host, port)
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to dial server: %v", err)
 	}
 	s.conn = conn
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	s.SetLogger(opts.Logger)
 	go s.handleResponses()
-	return s, nil
+	return &s, nil
 }
 
 // Close closes server connection.
@@ -80,6 +118,28 @@ func (s *Server) Address() string {
 	return s.conn.RemoteAddr().String()
 }
 
+// SetLogger sets logger for server connection messages,
+// messages are logged with the server address.
+func (s *Server) SetLogger(l *slog.Logger) {
+	s.logger = l.With("server", s.Address())
+}
+
+// Logger returns logger for server connection messages.
+func (s *Server) Logger() *slog.Logger {
+	return s.logger
+}
+
+// SetMetrics sets metrics for server requests and responses,
+// metrics are not collected if nil.
+func (s *Server) SetMetrics(m *Metrics) {
+	s.metrics = m
+}
+
+// Metrics returns metrics for server requests and responses.
+func (s *Server) Metrics() *Metrics {
+	return s.metrics
+}
+
 // SetOnServerResponseFunc sets function triggered on server reponse.
 func (s *Server) SetOnResponseFunc(f func(r response.Response)) {
 	s.onResponse = f
@@ -89,15 +149,57 @@ func (s *Server) SetOnResponseFunc(f func(r response.Response)) {
 // If error will occure while writing data using server connection
 // then the server connection will be closed and error returned.
 func (s *Server) Send(req request.Request) error {
+	return s.SendContext(context.Background(), req)
+}
+
+// SendContext sends specified request to the server.
+// Writing is aborted if specified context is done before the request
+// is written.
+// If error will occure while writing data using server connection
+// then the server connection will be closed and error returned.
+func (s *Server) SendContext(ctx context.Context, req request.Request) error {
 	text, err := request.Marshal(&req)
 	if err != nil {
+		s.Metrics().addSendError()
 		return fmt.Errorf("Unable to marshal request: %v", err)
 	}
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	if ctx.Err() != nil {
+		s.Metrics().addSendError()
+		return fmt.Errorf("Unable to write request: %v", ctx.Err())
+	}
+	deadline, _ := ctx.Deadline()
+	s.conn.SetWriteDeadline(deadline)
+	if ctx.Done() != nil {
+		// Abort write on context cancel.
+		// Write mutex is released after the watcher exits and the
+		// deadline is cleared, so the watcher can't abort later writes.
+		written := make(chan struct{})
+		stopped := make(chan struct{})
+		defer func() {
+			close(written)
+			<-stopped
+			s.conn.SetWriteDeadline(time.Time{})
+		}()
+		go func() {
+			defer close(stopped)
+			select {
+			case <-ctx.Done():
+				s.conn.SetWriteDeadline(time.Now())
+			case <-written:
+			}
+		}()
+	}
 	err = s.conn.WriteMessage(websocket.TextMessage, []byte(text))
 	if err != nil {
+		s.Metrics().addSendError()
 		s.Close()
 		return fmt.Errorf("Unable to write request: %v", err)
 	}
+	types := requestTypes(req)
+	s.Metrics().addRequest(types...)
+	s.Logger().Debug("request sent", "request", strings.Join(types, ","))
 	return nil
 }
 
@@ -107,25 +209,49 @@ func (s *Server) handleResponses() {
 	for !s.Closed() {
 		_, msg, err := s.conn.ReadMessage()
 		if err != nil {
-			log.Printf("Server response: Unable to read from the server: %v", err)
+			if s.Closed() {
+				return
+			}
+			s.Logger().Error("unable to read from the server", "error", err)
+			s.Close()
 			return
 		}
 		resp, err := response.Unmarshal(string(msg))
 		if err != nil {
-			log.Printf("Server response: Unable to unmarshal server response: %v",
-				err)
+			s.Logger().Error("unable to unmarshal server response", "error", err)
 			continue
 		}
+		s.Metrics().addResponse()
 		if s.onResponse != nil {
 			go s.onResponse(resp)
 		}
 		if resp.Closed {
 			err := s.Close()
 			if err != nil {
-				log.Printf("Server response: unable to close connection: %v",
-					err)
+				s.Logger().Error("unable to close connection", "error", err)
 			}
 			return
 		}
 	}
 }
+
+// requestTypes returns types of all requests in specified
+// request.
+func requestTypes(req request.Request) (types []string) {
+	add := func(name string, n int) {
+		if n > 0 {
+			types = append(types, name)
+		}
+	}
+	add("login", len(req.Login))
+	add("move", len(req.Move))
+	add("chat", len(req.Chat))
+	add("target", len(req.Target))
+	add("use", len(req.Use))
+	add("accept", len(req.Accept))
+	add("transfer", len(req.Transfer))
+	add("dialog-answer", len(req.DialogAnswer))
+	add("dialog-end", len(req.DialogEnd))
+	add("command", len(req.Command))
+	return
+}
@@ -0,0 +1,241 @@
+/*
+ * state.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/isangeles/flame/effect"
+)
+
+// Version of the AI state format.
+const StateVersion = 1
+
+// Struct for AI state.
+type State struct {
+	Version    int                       `json:"version"`
+	Characters map[string]CharacterState `json:"characters"`
+}
+
+// Struct for AI character state.
+type CharacterState struct {
+	ID               string       `json:"id"`
+	Serial           string       `json:"serial"`
+	Facing           float64      `json:"facing"`
+	TargetID         string       `json:"target-id,omitempty"`
+	TargetSerial     string       `json:"target-serial,omitempty"`
+	TradeSpent       int          `json:"trade-spent"`
+	RestockTimer     int64        `json:"restock-timer"`
+	Escort           *EscortState `json:"escort,omitempty"`
+	EscortFlagUsed   bool         `json:"escort-flag-used"`
+	LastTalkerID     string       `json:"last-talker-id,omitempty"`
+	LastTalkerSerial string       `json:"last-talker-serial,omitempty"`
+}
+
+// Struct for escort state.
+type EscortState struct {
+	LeaderID       string  `json:"leader-id"`
+	LeaderSerial   string  `json:"leader-serial"`
+	Distance       float64 `json:"distance"`
+	GiveUpDistance float64 `json:"give-up-distance"`
+	Timeout        int64   `json:"timeout"`
+	Timer          int64   `json:"timer"`
+}
+
+// LoadState loads AI state from the JSON file with specified path.
+// Returns empty state if the file doesn't exist.
+func LoadState(path string) (*State, error) {
+	s := State{
+		Version:    StateVersion,
+		Characters: make(map[string]CharacterState),
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read state file: %v", err)
+	}
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal state: %v", err)
+	}
+	if s.Version > StateVersion {
+		return nil, fmt.Errorf("Unsupported state version: %d", s.Version)
+	}
+	if s.Characters == nil {
+		s.Characters = make(map[string]CharacterState)
+	}
+	s.Version = StateVersion
+	return &s, nil
+}
+
+// Save saves state to the JSON file with specified path.
+// State is written to the temporary file first, and then moved
+// to specified path.
+func (s *State) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Unable to marshal state: %v", err)
+	}
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, data, 0640)
+	if err != nil {
+		return fmt.Errorf("Unable to write state file: %v", err)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("Unable to move state file: %v", err)
+	}
+	return nil
+}
+
+// State returns current state of the character.
+func (c *Character) State() CharacterState {
+	c.tradeMutex.Lock()
+	defer c.tradeMutex.Unlock()
+	s := CharacterState{
+		ID:               c.ID(),
+		Serial:           c.Serial(),
+		Facing:           c.facing,
+		TradeSpent:       c.tradeSpent,
+		RestockTimer:     c.restockTimer,
+		EscortFlagUsed:   c.escortFlagUsed,
+		LastTalkerID:     c.lastTalkerID,
+		LastTalkerSerial: c.lastTalkerSerial,
+	}
+	if len(c.Targets()) > 0 {
+		s.TargetID, s.TargetSerial = c.Targets()[0].ID(), c.Targets()[0].Serial()
+	}
+	if c.escort != nil {
+		s.Escort = &EscortState{
+			LeaderID:       c.escort.LeaderID,
+			LeaderSerial:   c.escort.LeaderSerial,
+			Distance:       c.escort.Distance,
+			GiveUpDistance: c.escort.GiveUpDistance,
+			Timeout:        c.escort.Timeout,
+			Timer:          c.escort.timer,
+		}
+	}
+	return s
+}
+
+// SetState restores specified state of the character.
+// Target from the state is set only if it exists in the game
+// module.
+func (c *Character) SetState(s CharacterState) {
+	c.facing = s.Facing
+	c.tradeMutex.Lock()
+	c.tradeSpent = s.TradeSpent
+	c.tradeMutex.Unlock()
+	c.restockTimer = s.RestockTimer
+	c.escortFlagUsed = s.EscortFlagUsed
+	c.lastTalkerID, c.lastTalkerSerial = s.LastTalkerID, s.LastTalkerSerial
+	c.escort = nil
+	if s.Escort != nil {
+		c.escort = &Escort{
+			LeaderID:       s.Escort.LeaderID,
+			LeaderSerial:   s.Escort.LeaderSerial,
+			Distance:       s.Escort.Distance,
+			GiveUpDistance: s.Escort.GiveUpDistance,
+			Timeout:        s.Escort.Timeout,
+			timer:          s.Escort.Timer,
+		}
+	}
+	if len(s.TargetID) < 1 {
+		return
+	}
+	if tar, ok := c.game.Object(s.TargetID, s.TargetSerial).(effect.Target); ok {
+		c.SetTarget(tar)
+	}
+}
+
+// SetState sets AI state restored for characters added
+// to the game.
+// States of characters already in the game are restored
+// immediately.
+func (g *Game) SetState(s *State) {
+	g.stateMutex.Lock()
+	defer g.stateMutex.Unlock()
+	g.state = s
+	for _, c := range g.Characters() {
+		g.restoreState(c)
+	}
+}
+
+// State returns current AI state of the game, with states
+// of all game characters and saved states of characters not
+// restored yet.
+func (g *Game) State() *State {
+	g.stateMutex.Lock()
+	defer g.stateMutex.Unlock()
+	s := State{
+		Version:    StateVersion,
+		Characters: make(map[string]CharacterState),
+	}
+	if g.state != nil {
+		for k, cs := range g.state.Characters {
+			s.Characters[k] = cs
+		}
+	}
+	for _, c := range g.Characters() {
+		s.Characters[c.ID()+c.Serial()] = c.State()
+	}
+	return &s
+}
+
+// restoreState restores saved state of specified character,
+// if available.
+// Restored state is removed from the saved state.
+// State mutex should be locked before calling this function.
+func (g *Game) restoreState(c *Character) {
+	if g.state == nil {
+		return
+	}
+	cs, ok := g.state.Characters[c.ID()+c.Serial()]
+	if !ok {
+		return
+	}
+	c.SetState(cs)
+	delete(g.state.Characters, c.ID()+c.Serial())
+}
+
+// updateState saves AI state of the game if the save
+// timer elapsed.
+func (ai *AI) updateState(delta int64) {
+	if len(ai.statePath) < 1 {
+		return
+	}
+	ai.stateTimer += delta
+	if ai.stateTimer < ai.Game().Config().StateSaveFreq {
+		return
+	}
+	ai.stateTimer = 0
+	err := ai.Game().State().Save(ai.statePath)
+	if err != nil {
+		ai.Logger().Error("unable to save state", "path", ai.statePath, "error", err)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ * state_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+)
+
+// TestStateRestore tests saving, loading and restoring AI state.
+func TestStateRestore(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	char := NewCharacter(character.New(charData), game)
+	game.AddCharacter(char)
+	char.tradeSpent = 120
+	char.restockTimer = 500
+	char.SetEscort(NewEscort("player", "0"))
+	path := filepath.Join(t.TempDir(), "state.json")
+	err := game.State().Save(path)
+	if err != nil {
+		t.Fatalf("Unable to save state: %v", err)
+	}
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Unable to load state: %v", err)
+	}
+	game = NewGame(mod, nil)
+	game.SetState(state)
+	char = NewCharacter(char.Character, game)
+	game.AddCharacter(char)
+	if char.tradeSpent != 120 || char.restockTimer != 500 {
+		t.Errorf("Invalid trade counters: %d %d", char.tradeSpent, char.restockTimer)
+	}
+	if char.Escort() == nil || char.Escort().LeaderID != "player" {
+		t.Errorf("Escort not restored")
+	}
+	// Unsupported version.
+	err = os.WriteFile(path, []byte(`{"version":999}`), 0640)
+	if err != nil {
+		t.Fatalf("Unable to write state: %v", err)
+	}
+	_, err = LoadState(path)
+	if err == nil {
+		t.Errorf("No error for unsupported state version")
+	}
+}
@@ -0,0 +1,286 @@
+/*
+ * trace.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/isangeles/flame/effect"
+	"github.com/isangeles/flame/skill"
+)
+
+// Version of the decision trace format.
+const TraceVersion = 1
+
+// Decision actions.
+const (
+	ActionNone       = "none"
+	ActionFollow     = "follow"
+	ActionLoot       = "loot"
+	ActionReturn     = "return"
+	ActionMoveAround = "move-around"
+	ActionChat       = "chat"
+	ActionTarget     = "target"
+	ActionUntarget   = "untarget"
+	ActionApproach   = "approach"
+	ActionUseSkill   = "use-skill"
+	ActionFlee       = "flee"
+)
+
+// Decision reasons.
+const (
+	ReasonIdle           = "idle"
+	ReasonBusy           = "busy"
+	ReasonGroupFormation = "group-formation"
+	ReasonLootInRange    = "loot-in-range"
+	ReasonAwayFromAnchor = "away-from-anchor"
+	ReasonMoveTimer      = "move-timer"
+	ReasonChatTimer      = "chat-timer"
+	ReasonNoArea         = "no-area"
+	ReasonNoHostile      = "no-hostile-noticed"
+	ReasonHostileNoticed = "hostile-noticed"
+	ReasonTargetDead     = "target-dead"
+	ReasonDeaggro        = "deaggro-distance"
+	ReasonNoSkill        = "no-skill"
+	ReasonOutOfRange     = "out-of-range"
+	ReasonCooldown       = "cooldown"
+	ReasonSkillReady     = "skill-ready"
+	ReasonLeaderDeath    = "leader-death"
+)
+
+// Struct for decision trace, trace writes entries with AI
+// decisions for traced NPCs in JSON lines format.
+type Trace struct {
+	writer  io.Writer
+	encoder *json.Encoder
+	filter  []string
+	mutex   sync.Mutex
+}
+
+// Struct for decision trace entry with inputs and decisions
+// of single NPC in single AI update.
+type TraceEntry struct {
+	Version    int              `json:"version"`
+	Tick       int64            `json:"tick"`
+	Time       int64            `json:"time"`
+	ID         string           `json:"id"`
+	Serial     string           `json:"serial"`
+	PosX       float64          `json:"pos-x"`
+	PosY       float64          `json:"pos-y"`
+	Targets    []TraceObject    `json:"targets"`
+	Candidates []TraceCandidate `json:"candidates"`
+	Skills     []TraceSkill     `json:"skills"`
+	Decisions  []TraceDecision  `json:"decisions"`
+}
+
+// Struct for traced object.
+type TraceObject struct {
+	ID     string `json:"id"`
+	Serial string `json:"serial"`
+}
+
+// Struct for target candidate in NPC aggro range.
+type TraceCandidate struct {
+	ID       string  `json:"id"`
+	Serial   string  `json:"serial"`
+	Distance float64 `json:"distance"`
+	Hostile  bool    `json:"hostile"`
+	Noticed  bool    `json:"noticed"`
+}
+
+// Struct for combat skill candidate, skills are listed in the
+// selection order, skill with the highest score is selected.
+type TraceSkill struct {
+	ID       string  `json:"id"`
+	Ready    bool    `json:"ready"`
+	Score    float64 `json:"score"`
+	Selected bool    `json:"selected"`
+}
+
+// Struct for NPC decision.
+type TraceDecision struct {
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// NewTrace creates new decision trace written to specified writer.
+// Only NPCs matching specified filter values are traced, filter value
+// is character ID optionally followed by the serial value separated
+// with '#', e.g. 'wolf#0'.
+// All NPCs are traced if there are no filter values.
+func NewTrace(w io.Writer, filter ...string) *Trace {
+	t := Trace{
+		writer:  w,
+		encoder: json.NewEncoder(w),
+		filter:  filter,
+	}
+	return &t
+}
+
+// OpenTrace creates new decision trace appended to the file with
+// specified path.
+func OpenTrace(path string, filter ...string) (*Trace, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to open trace file: %v", err)
+	}
+	return NewTrace(file, filter...), nil
+}
+
+// Close closes trace writer, if it's closable.
+func (t *Trace) Close() error {
+	if c, ok := t.writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Traced checks if specified character is traced.
+func (t *Trace) Traced(c *Character) bool {
+	if len(t.filter) < 1 {
+		return true
+	}
+	for _, f := range t.filter {
+		id, serial, withSerial := strings.Cut(f, "#")
+		if c.ID() == id && (!withSerial || c.Serial() == serial) {
+			return true
+		}
+	}
+	return false
+}
+
+// add writes specified entry to the trace.
+func (t *Trace) add(e *TraceEntry) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	err := t.encoder.Encode(e)
+	if err != nil {
+		return fmt.Errorf("Unable to write trace entry: %v", err)
+	}
+	return nil
+}
+
+// SetTrace sets decision trace for the AI, trace is disabled
+// if nil.
+func (ai *AI) SetTrace(t *Trace) {
+	ai.trace = t
+}
+
+// Trace returns decision trace of the AI or nil if trace is
+// disabled.
+func (ai *AI) Trace() *Trace {
+	return ai.trace
+}
+
+// traceEntry creates new trace entry with current inputs of specified
+// NPC.
+// Returns nil if trace is disabled or the NPC is not traced.
+func (ai *AI) traceEntry(npc *Character) *TraceEntry {
+	if ai.trace == nil || !ai.trace.Traced(npc) {
+		return nil
+	}
+	e := TraceEntry{
+		Version:    TraceVersion,
+		Tick:       ai.tick,
+		Time:       ai.time,
+		ID:         npc.ID(),
+		Serial:     npc.Serial(),
+		Targets:    make([]TraceObject, 0),
+		Candidates: make([]TraceCandidate, 0),
+		Skills:     make([]TraceSkill, 0),
+		Decisions:  make([]TraceDecision, 0),
+	}
+	e.PosX, e.PosY = npc.Position()
+	for _, t := range npc.Targets() {
+		e.Targets = append(e.Targets, TraceObject{t.ID(), t.Serial()})
+	}
+	return &e
+}
+
+// writeTrace writes specified entry to the AI trace.
+// Entry without decisions is written with no action decision.
+func (ai *AI) writeTrace(e *TraceEntry) {
+	if e == nil {
+		return
+	}
+	if len(e.Decisions) < 1 {
+		e.decide(ActionNone, ReasonIdle)
+	}
+	err := ai.trace.add(e)
+	if err != nil {
+		ai.Logger().Error("unable to trace decisions", "error", err)
+	}
+}
+
+// decide adds decision with specified action and reason to the entry.
+func (e *TraceEntry) decide(action, reason string) {
+	if e == nil {
+		return
+	}
+	e.Decisions = append(e.Decisions, TraceDecision{action, reason})
+}
+
+// addCandidate adds specified target candidate of specified NPC
+// to the entry.
+func (e *TraceEntry) addCandidate(npc *Character, tar effect.Target, hostile, noticed bool) {
+	if e == nil {
+		return
+	}
+	npcX, npcY := npc.Position()
+	tarX, tarY := tar.Position()
+	c := TraceCandidate{
+		ID:       tar.ID(),
+		Serial:   tar.Serial(),
+		Distance: math.Hypot(tarX-npcX, tarY-npcY),
+		Hostile:  hostile,
+		Noticed:  noticed,
+	}
+	e.Candidates = append(e.Candidates, c)
+}
+
+// addSkills adds combat skills of specified NPC to the entry,
+// with specified selected skill and skill scores in the skills
+// order.
+func (e *TraceEntry) addSkills(npc *Character, selected *skill.Skill, scores []float64) {
+	if e == nil {
+		return
+	}
+	for i, s := range npc.Skills() {
+		ts := TraceSkill{
+			ID:       s.ID(),
+			Ready:    s.UseAction() != nil && s.UseAction().Cooldown() <= 0,
+			Selected: s == selected,
+		}
+		if i < len(scores) {
+			ts.Score = scores[i]
+		}
+		e.Skills = append(e.Skills, ts)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * trace_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+)
+
+// TestTrace tests writing decision trace entries.
+func TestTrace(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	char := NewCharacter(character.New(charData), game)
+	ai := New(game)
+	buf := new(bytes.Buffer)
+	ai.SetTrace(NewTrace(buf))
+	ai.tick = 7
+	entry := ai.traceEntry(char)
+	if entry == nil {
+		t.Fatalf("Character not traced")
+	}
+	entry.decide(ActionMoveAround, ReasonMoveTimer)
+	ai.writeTrace(entry)
+	ai.writeTrace(ai.traceEntry(char))
+	dec := json.NewDecoder(buf)
+	for i, want := range []TraceDecision{{ActionMoveAround, ReasonMoveTimer}, {ActionNone, ReasonIdle}} {
+		var e TraceEntry
+		err := dec.Decode(&e)
+		if err != nil {
+			t.Fatalf("Unable to decode entry %d: %v", i, err)
+		}
+		if e.Version != TraceVersion || e.Tick != 7 {
+			t.Errorf("Invalid entry %d: version %d, tick %d", i, e.Version, e.Tick)
+		}
+		if len(e.Decisions) != 1 || e.Decisions[0] != want {
+			t.Errorf("Invalid decisions of entry %d: %v", i, e.Decisions)
+		}
+	}
+}
+
+// TestTraceFilter tests tracing only characters matching
+// trace filter.
+func TestTraceFilter(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	char := NewCharacter(character.New(charData), game)
+	if NewTrace(nil, "other").Traced(char) {
+		t.Errorf("Character traced with not matching filter")
+	}
+	if NewTrace(nil, char.ID()+"#other").Traced(char) {
+		t.Errorf("Character traced with not matching serial")
+	}
+	if !NewTrace(nil, char.ID()).Traced(char) {
+		t.Errorf("Character not traced with matching ID")
+	}
+	if !NewTrace(nil, char.ID()+"#"+char.Serial()).Traced(char) {
+		t.Errorf("Character not traced with matching ID and serial")
+	}
+}
@@ -0,0 +1,335 @@
+/*
+ * trade.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/item"
+)
+
+// Trade decision reasons.
+const (
+	TradeAccepted       = "accepted"
+	TradeValueTooLow    = "value-too-low"
+	TradeItemRefused    = "item-refused"
+	TradeBuyLimit       = "buy-limit"
+	TradeBudgetExceeded = "budget-exceeded"
+	TradeHostileBuyer   = "hostile-buyer"
+)
+
+// Text IDs for merchant chat messages on trade rejection.
+const (
+	TradeRejectTextPrefix = "trade_reject_"
+	TradeCounterOfferText = "trade_counter_offer"
+)
+
+// Item categories.
+const (
+	ArmorCategory  = "armor"
+	WeaponCategory = "weapon"
+	MiscCategory   = "misc"
+)
+
+// Struct for trade offer between AI character
+// and other character.
+type Trade struct {
+	ID     int
+	Seller *Character
+	Buyer  *character.Character
+	// Items bought by the buyer from the seller.
+	ItemsBuy []item.Item
+	// Items sold by the buyer to the seller.
+	ItemsSell []item.Item
+}
+
+// Struct for trade evaluation result.
+type TradeResult struct {
+	Accept bool
+	Reason string
+	// Value of items bought by the buyer.
+	BuyValue int
+	// Value of items sold by the buyer.
+	SellValue int
+}
+
+// Interface for merchant trade policies.
+type TradePolicy interface {
+	Evaluate(t *Trade) TradeResult
+}
+
+// Struct for configurable merchant trade policy.
+type MerchantPolicy struct {
+	// Price multiplier for items sold by the merchant.
+	BuyMarkup float64
+	// Price multiplier for items bought by the merchant.
+	SellMarkup float64
+	// IDs of items bought by the merchant, if empty all items
+	// are allowed.
+	AllowItems []string
+	// IDs of items never bought by the merchant.
+	RefuseItems []string
+	// Categories of items bought by the merchant, if empty
+	// all categories are allowed.
+	AllowCategories []string
+	// Categories of items never bought by the merchant.
+	RefuseCategories []string
+	// Maximal value of currency paid by the merchant,
+	// unlimited if zero.
+	Budget int
+	// Maximal number of items with the same ID in the merchant
+	// inventory after buying, unlimited if zero.
+	BuyLimit int
+	// Price discount for friendly buyers(0.1 = 10%).
+	FriendlyDiscount float64
+}
+
+// DefaultMerchantPolicy returns merchant policy which accepts
+// all trades with value of sold items at least equal to the
+// value of bought items.
+func DefaultMerchantPolicy() *MerchantPolicy {
+	return &MerchantPolicy{BuyMarkup: 1, SellMarkup: 1}
+}
+
+// Evaluate evaluates specified trade.
+func (mp *MerchantPolicy) Evaluate(t *Trade) TradeResult {
+	res := TradeResult{}
+	if t.Seller.Hostile(t.Buyer) {
+		res.Reason = TradeHostileBuyer
+		return res
+	}
+	discount := 0.0
+	if t.Seller.Friendly(t.Buyer) {
+		discount = mp.FriendlyDiscount
+	}
+	res.BuyValue = int(math.Ceil(float64(t.Seller.itemsValue(t.ItemsBuy, mp.BuyMarkup)) * (1 - discount)))
+	res.SellValue = t.Seller.itemsValue(t.ItemsSell, mp.SellMarkup)
+	for _, it := range t.ItemsSell {
+		if currency(it) {
+			continue
+		}
+		if !mp.allowed(it) {
+			res.Reason = TradeItemRefused
+			return res
+		}
+	}
+	if mp.BuyLimit > 0 {
+		for id, count := range itemsCount(t.ItemsSell) {
+			if count+t.Seller.inventoryCount(id) > mp.BuyLimit {
+				res.Reason = TradeBuyLimit
+				return res
+			}
+		}
+	}
+	if mp.Budget > 0 {
+		paid := currencyValue(t.ItemsBuy) - currencyValue(t.ItemsSell)
+		if paid > 0 && t.Seller.TradeBudgetSpent()+paid > mp.Budget {
+			res.Reason = TradeBudgetExceeded
+			return res
+		}
+	}
+	if res.SellValue < res.BuyValue {
+		res.Reason = TradeValueTooLow
+		return res
+	}
+	res.Accept = true
+	res.Reason = TradeAccepted
+	return res
+}
+
+// allowed checks if specified item is allowed to be
+// bought by the merchant.
+func (mp *MerchantPolicy) allowed(it item.Item) bool {
+	cat := ItemCategory(it)
+	if contains(mp.RefuseItems, it.ID()) || contains(mp.RefuseCategories, cat) {
+		return false
+	}
+	if len(mp.AllowItems) < 1 && len(mp.AllowCategories) < 1 {
+		return true
+	}
+	return contains(mp.AllowItems, it.ID()) || contains(mp.AllowCategories, cat)
+}
+
+// CounterOffer returns items requested by the buyer that the
+// seller would sell for the items offered by the buyer.
+// Requested items with the highest seller price are removed from
+// the trade until it's accepted by specified policy.
+// Returns nil if there is no such items.
+func CounterOffer(t *Trade, p TradePolicy) []item.Item {
+	items := append([]item.Item{}, t.ItemsBuy...)
+	prices := make(map[item.Item]float64)
+	for _, it := range items {
+		prices[it] = t.Seller.ItemPrice(it)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return prices[items[i]] > prices[items[j]]
+	})
+	offer := *t
+	for len(items) > 0 {
+		items = items[1:]
+		offer.ItemsBuy = items
+		if len(items) > 0 && p.Evaluate(&offer).Accept {
+			return items
+		}
+	}
+	return nil
+}
+
+// RejectTrade informs the buyer about rejection of specified trade.
+// Fire protocol has no request for declining trades, so the rejection
+// reason and counter-offer are sent as the character chat messages,
+// if enabled in the character profile.
+func (c *Character) RejectTrade(t *Trade, res TradeResult) {
+	p := c.Profile()
+	if !p.TradeRejectChat {
+		return
+	}
+	c.AddChatMessage(TradeRejectTextPrefix + res.Reason)
+	if !p.TradeCounterOffer || res.Reason != TradeValueTooLow {
+		return
+	}
+	items := CounterOffer(t, c.TradePolicy())
+	if len(items) < 1 {
+		return
+	}
+	ids := make([]string, 0)
+	for _, it := range items {
+		ids = append(ids, it.ID())
+	}
+	c.AddChatMessage(fmt.Sprintf("%s:%s", TradeCounterOfferText,
+		strings.Join(ids, ";")))
+}
+
+// TradePolicy returns trade policy of the character.
+// Returns policy set with SetTradePolicy or policy from the
+// character profile if not set.
+func (c *Character) TradePolicy() TradePolicy {
+	if c.tradePolicy != nil {
+		return c.tradePolicy
+	}
+	return c.Profile().Trade
+}
+
+// SetTradePolicy sets trade policy for the character.
+func (c *Character) SetTradePolicy(p TradePolicy) {
+	c.tradePolicy = p
+}
+
+// TradeBudgetSpent returns value of currency paid by the character
+// in accepted trades.
+func (c *Character) TradeBudgetSpent() int {
+	c.tradeMutex.Lock()
+	defer c.tradeMutex.Unlock()
+	return c.tradeSpent
+}
+
+// tradeAccepted updates character state after accepting
+// specified trade.
+func (c *Character) tradeAccepted(t *Trade) {
+	c.tradeMutex.Lock()
+	defer c.tradeMutex.Unlock()
+	c.tradeSpent += currencyValue(t.ItemsBuy) - currencyValue(t.ItemsSell)
+	if c.tradeSpent < 0 {
+		c.tradeSpent = 0
+	}
+}
+
+// inventoryCount returns number of items with specified ID
+// in the character inventory.
+func (c *Character) inventoryCount(id string) (count int) {
+	for _, it := range c.Inventory().Items() {
+		if it.Item.ID() == id {
+			count++
+		}
+	}
+	return
+}
+
+// ItemCategory returns trade category of specified item.
+func ItemCategory(it item.Item) string {
+	switch it.(type) {
+	case *item.Armor:
+		return ArmorCategory
+	case *item.Weapon:
+		return WeaponCategory
+	default:
+		return MiscCategory
+	}
+}
+
+// itemsValue returns value of specified items for the character,
+// with specified price multiplier.
+// Prices and the multiplier are not applied to the currency items.
+func (c *Character) itemsValue(items []item.Item, markup float64) int {
+	value := 0.0
+	for _, it := range items {
+		if currency(it) {
+			value += float64(it.Value())
+			continue
+		}
+		value += c.ItemPrice(it) * markup
+	}
+	return int(math.Round(value))
+}
+
+// currencyValue returns value of currency items
+// from specified items.
+func currencyValue(items []item.Item) (value int) {
+	for _, it := range items {
+		if currency(it) {
+			value += it.Value()
+		}
+	}
+	return
+}
+
+// itemsCount returns number of items for each item ID.
+func itemsCount(items []item.Item) map[string]int {
+	count := make(map[string]int)
+	for _, it := range items {
+		count[it.ID()]++
+	}
+	return count
+}
+
+// currency checks if specified item is a currency item.
+func currency(it item.Item) bool {
+	cur, ok := it.(interface{ Currency() bool })
+	return ok && cur.Currency()
+}
+
+// contains checks if specified values contain
+// specified value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+ * trade_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+	"github.com/isangeles/flame/item"
+)
+
+var (
+	swordData = res.MiscItemData{ID: "sword", Value: 10}
+	junkData  = res.MiscItemData{ID: "junk", Value: 10}
+)
+
+// TestMerchantPolicyEvaluate tests evaluating trades
+// with the merchant policy.
+func TestMerchantPolicyEvaluate(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	seller := NewCharacter(character.New(charData), game)
+	buyer := character.New(charData)
+	trade := Trade{
+		Seller:    seller,
+		Buyer:     buyer,
+		ItemsBuy:  []item.Item{item.NewMisc(swordData)},
+		ItemsSell: []item.Item{item.NewMisc(junkData)},
+	}
+	policy := DefaultMerchantPolicy()
+	if res := policy.Evaluate(&trade); !res.Accept {
+		t.Errorf("Trade not accepted: %s", res.Reason)
+	}
+	policy.BuyMarkup = 2
+	if res := policy.Evaluate(&trade); res.Accept || res.Reason != TradeValueTooLow {
+		t.Errorf("Invalid result for trade with buy markup: %v", res)
+	}
+	policy.BuyMarkup = 1
+	policy.RefuseItems = []string{junkData.ID}
+	if res := policy.Evaluate(&trade); res.Accept || res.Reason != TradeItemRefused {
+		t.Errorf("Invalid result for trade with refused item: %v", res)
+	}
+}
+
+// TestCounterOffer tests creating counter-offer for
+// rejected trade.
+func TestCounterOffer(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	seller := NewCharacter(character.New(charData), game)
+	buyer := character.New(charData)
+	trade := Trade{
+		Seller: seller,
+		Buyer:  buyer,
+		ItemsBuy: []item.Item{
+			item.NewMisc(res.MiscItemData{ID: "expensive", Value: 20}),
+			item.NewMisc(swordData),
+		},
+		ItemsSell: []item.Item{item.NewMisc(junkData)},
+	}
+	items := CounterOffer(&trade, DefaultMerchantPolicy())
+	if len(items) != 1 || items[0].ID() != swordData.ID {
+		t.Errorf("Invalid counter-offer items: %v", items)
+	}
+}
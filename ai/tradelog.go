@@ -0,0 +1,263 @@
+/*
+ * tradelog.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/isangeles/flame/item"
+)
+
+// Struct for trade log entry.
+type TradeLogEntry struct {
+	Time         time.Time      `json:"time"`
+	TradeID      int            `json:"trade-id"`
+	SellerID     string         `json:"seller-id"`
+	SellerSerial string         `json:"seller-serial"`
+	BuyerID      string         `json:"buyer-id"`
+	BuyerSerial  string         `json:"buyer-serial"`
+	ItemsBuy     []TradeLogItem `json:"items-buy"`
+	ItemsSell    []TradeLogItem `json:"items-sell"`
+	BuyValue     int            `json:"buy-value"`
+	SellValue    int            `json:"sell-value"`
+	Accept       bool           `json:"accept"`
+	Reason       string         `json:"reason"`
+}
+
+// Struct for trade log item.
+type TradeLogItem struct {
+	ID     string `json:"id"`
+	Serial string `json:"serial"`
+	Value  int    `json:"value"`
+}
+
+// Struct for trade summary.
+type TradeSummary struct {
+	Trades    int
+	Accepted  int
+	Rejected  int
+	BuyValue  int
+	SellValue int
+}
+
+// Struct for trade audit log.
+// Log is written in JSON lines format and rotated after
+// reaching the maximal size.
+type TradeLog struct {
+	path    string
+	maxSize int64
+	backups int
+	mutex   sync.Mutex
+}
+
+// NewTradeLog creates new trade log written to the file with
+// specified path.
+// The file is rotated after reaching specified maximal size
+// in bytes(no rotation if zero), and specified number of
+// rotated files is kept.
+func NewTradeLog(path string, maxSize int64, backups int) *TradeLog {
+	tl := TradeLog{
+		path:    path,
+		maxSize: maxSize,
+		backups: backups,
+	}
+	return &tl
+}
+
+// Path returns path to the log file.
+func (tl *TradeLog) Path() string {
+	return tl.path
+}
+
+// Add adds entry for specified trade and its evaluation
+// result to the log.
+func (tl *TradeLog) Add(t *Trade, res TradeResult) error {
+	entry := TradeLogEntry{
+		Time:         time.Now(),
+		TradeID:      t.ID,
+		SellerID:     t.Seller.ID(),
+		SellerSerial: t.Seller.Serial(),
+		BuyerID:      t.Buyer.ID(),
+		BuyerSerial:  t.Buyer.Serial(),
+		ItemsBuy:     tradeLogItems(t.ItemsBuy),
+		ItemsSell:    tradeLogItems(t.ItemsSell),
+		BuyValue:     res.BuyValue,
+		SellValue:    res.SellValue,
+		Accept:       res.Accept,
+		Reason:       res.Reason,
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("Unable to marshal log entry: %v", err)
+	}
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+	err = tl.rotate()
+	if err != nil {
+		return fmt.Errorf("Unable to rotate log: %v", err)
+	}
+	file, err := os.OpenFile(tl.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("Unable to open log file: %v", err)
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	if err != nil {
+		return fmt.Errorf("Unable to write log entry: %v", err)
+	}
+	return nil
+}
+
+// rotate rotates log file if it exceeds the maximal size.
+func (tl *TradeLog) rotate() error {
+	if tl.maxSize <= 0 {
+		return nil
+	}
+	info, err := os.Stat(tl.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Unable to check log file: %v", err)
+	}
+	if info.Size() < tl.maxSize {
+		return nil
+	}
+	if tl.backups < 1 {
+		return os.Remove(tl.path)
+	}
+	os.Remove(rotatedPath(tl.path, tl.backups))
+	for i := tl.backups - 1; i > 0; i-- {
+		err := os.Rename(rotatedPath(tl.path, i), rotatedPath(tl.path, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(tl.path, rotatedPath(tl.path, 1))
+}
+
+// ReadTradeLog reads all entries from the trade log file with
+// specified path and its rotated files, in order from the oldest
+// entry.
+func ReadTradeLog(path string) ([]TradeLogEntry, error) {
+	paths := []string{path}
+	for i := 1; ; i++ {
+		_, err := os.Stat(rotatedPath(path, i))
+		if err != nil {
+			break
+		}
+		paths = append([]string{rotatedPath(path, i)}, paths...)
+	}
+	entries := make([]TradeLogEntry, 0)
+	for _, p := range paths {
+		file, err := os.Open(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Unable to open log file: %v", err)
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var entry TradeLogEntry
+			err := json.Unmarshal(scanner.Bytes(), &entry)
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("Unable to unmarshal log entry: %s: %v",
+					p, err)
+			}
+			entries = append(entries, entry)
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("Unable to read log file: %s: %v", p, err)
+		}
+	}
+	return entries, nil
+}
+
+// SummarizeTrades summarizes trades from specified log entries
+// in specified time range, per merchant and per player.
+// Summary keys are characters IDs and serials separated by space.
+// Zero time values are not used to limit the time range.
+func SummarizeTrades(entries []TradeLogEntry, from, to time.Time) (merchants,
+	players map[string]*TradeSummary) {
+	merchants = make(map[string]*TradeSummary)
+	players = make(map[string]*TradeSummary)
+	for _, e := range entries {
+		if !from.IsZero() && e.Time.Before(from) {
+			continue
+		}
+		if !to.IsZero() && e.Time.After(to) {
+			continue
+		}
+		merchant := fmt.Sprintf("%s %s", e.SellerID, e.SellerSerial)
+		if merchants[merchant] == nil {
+			merchants[merchant] = new(TradeSummary)
+		}
+		merchants[merchant].add(e)
+		player := fmt.Sprintf("%s %s", e.BuyerID, e.BuyerSerial)
+		if players[player] == nil {
+			players[player] = new(TradeSummary)
+		}
+		players[player].add(e)
+	}
+	return
+}
+
+// add adds specified entry to the summary.
+func (ts *TradeSummary) add(e TradeLogEntry) {
+	ts.Trades++
+	if !e.Accept {
+		ts.Rejected++
+		return
+	}
+	ts.Accepted++
+	ts.BuyValue += e.BuyValue
+	ts.SellValue += e.SellValue
+}
+
+// tradeLogItems creates trade log items for specified items.
+func tradeLogItems(items []item.Item) (logItems []TradeLogItem) {
+	for _, it := range items {
+		logItem := TradeLogItem{
+			ID:     it.ID(),
+			Serial: it.Serial(),
+			Value:  it.Value(),
+		}
+		logItems = append(logItems, logItem)
+	}
+	return
+}
+
+// rotatedPath returns path for rotated log file with
+// specified number.
+func rotatedPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
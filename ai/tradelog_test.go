@@ -0,0 +1,73 @@
+/*
+ * tradelog_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package ai
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data/res"
+	"github.com/isangeles/flame/item"
+)
+
+// TestTradeLog tests adding trades to the trade log
+// with rotation and summarizing logged trades.
+func TestTradeLog(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	game := NewGame(mod, nil)
+	seller := NewCharacter(character.New(charData), game)
+	trade := Trade{
+		Seller:   seller,
+		Buyer:    character.New(charData),
+		ItemsBuy: []item.Item{item.NewMisc(swordData)},
+	}
+	path := filepath.Join(t.TempDir(), "trades.log")
+	tradeLog := NewTradeLog(path, 1, 2)
+	results := []TradeResult{
+		TradeResult{Accept: true, Reason: TradeAccepted, BuyValue: 10},
+		TradeResult{Reason: TradeValueTooLow},
+		TradeResult{Accept: true, Reason: TradeAccepted, BuyValue: 10},
+	}
+	for _, r := range results {
+		err := tradeLog.Add(&trade, r)
+		if err != nil {
+			t.Fatalf("Unable to add trade log entry: %v", err)
+		}
+	}
+	entries, err := ReadTradeLog(path)
+	if err != nil {
+		t.Fatalf("Unable to read trade log: %v", err)
+	}
+	if len(entries) != len(results) {
+		t.Fatalf("Invalid number of log entries: %d", len(entries))
+	}
+	merchants, _ := SummarizeTrades(entries, time.Time{}, time.Time{})
+	summary := merchants[seller.ID()+" "+seller.Serial()]
+	if summary == nil || summary.Accepted != 2 || summary.Rejected != 1 || summary.BuyValue != 20 {
+		t.Errorf("Invalid merchant summary: %v", summary)
+	}
+}
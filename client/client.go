@@ -0,0 +1,327 @@
+/*
+ * client.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+// client package provides AI client for running Ignite AI
+// for single server and user entry.
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/data/res"
+
+	"github.com/isangeles/fire/request"
+	"github.com/isangeles/fire/response"
+
+	"github.com/isangeles/ignite/ai"
+	"github.com/isangeles/ignite/config"
+)
+
+// Struct for AI client of a single server and user entry.
+type Client struct {
+	conf       *config.Config
+	entry      config.Client
+	logger     *slog.Logger
+	profiles   []*ai.Profile
+	clearRes   bool
+	reputation *ai.Reputation
+	mutex      sync.Mutex
+	server     *ai.Server
+	ai         *ai.AI
+	state      *ai.State
+	trace      *ai.Trace
+	metrics    *ai.Metrics
+}
+
+// New creates new AI client for specified server and user entry
+// with specified configuration.
+// Configuration and profiles could be shared between clients and
+// should not be modified.
+func New(conf *config.Config, entry config.Client, profiles []*ai.Profile) (*Client, error) {
+	c := Client{
+		conf:     conf,
+		entry:    entry,
+		logger:   slog.Default(),
+		profiles: profiles,
+	}
+	if len(entry.Name) > 0 {
+		c.logger = c.logger.With("client", entry.Name)
+	}
+	rep, err := ai.LoadReputation(c.path(c.conf.ReputationPath))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load reputation: %v", err)
+	}
+	c.reputation = rep
+	if len(c.conf.StatePath) > 0 {
+		state, err := ai.LoadState(c.path(c.conf.StatePath))
+		if err != nil {
+			return nil, fmt.Errorf("Unable to load AI state: %v", err)
+		}
+		c.state = state
+	}
+	if len(c.conf.TracePath) > 0 {
+		trace, err := ai.OpenTrace(c.path(c.conf.TracePath), c.conf.TraceFilter...)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to open decision trace: %v", err)
+		}
+		c.trace = trace
+	}
+	return &c, nil
+}
+
+// SetLogger sets logger for client, game and server messages.
+func (c *Client) SetLogger(l *slog.Logger) {
+	c.logger = l
+}
+
+// SetClearResources enables clearing of the flame resources on each
+// module update from the server.
+// Resources are shared by all modules in the process, so this should
+// be enabled only if there is only one client running in the process.
+func (c *Client) SetClearResources(clear bool) {
+	c.clearRes = clear
+}
+
+// SetMetrics sets metrics for the client AI and server
+// connection, metrics are not collected if nil.
+func (c *Client) SetMetrics(m *ai.Metrics) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.metrics = m
+}
+
+// SetProfiles sets NPC behavior profiles for the client AI.
+func (c *Client) SetProfiles(profiles []*ai.Profile) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.profiles = profiles
+	if c.ai != nil {
+		c.ai.Game().SetProfiles(profiles)
+	}
+}
+
+// Entry returns server and user entry of the client.
+func (c *Client) Entry() config.Client {
+	return c.entry
+}
+
+// AI returns current AI of the client or nil if the client
+// didn't receive a game module from the server yet.
+func (c *Client) AI() *ai.AI {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.ai
+}
+
+// Run connects to the server and updates the AI until the server
+// connection is closed, then reconnects after the reconnect delay.
+// Returns after specified context is done.
+func (c *Client) Run(ctx context.Context) error {
+	for reconnect := false; ; reconnect = true {
+		if reconnect {
+			c.metrics.AddReconnect()
+		}
+		err := c.connect(ctx)
+		if err != nil {
+			c.logger.Error("unable to connect to the server", "error", err)
+		} else {
+			c.update(ctx)
+			c.disconnect()
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Duration(c.conf.ReconnectDelay) * time.Millisecond):
+		}
+	}
+}
+
+// connect connects and logs in to the server.
+// Connection is aborted after connect timeout.
+func (c *Client) connect(ctx context.Context) error {
+	timeout := time.Duration(c.conf.ConnectTimeout) * time.Millisecond
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	c.mutex.Lock()
+	opts := ai.DialOptions{
+		TLS:        c.entry.TLS,
+		Logger:     c.logger,
+		Metrics:    c.metrics,
+		OnResponse: c.handleResponse,
+	}
+	c.mutex.Unlock()
+	server, err := ai.DialContext(ctx, c.entry.Host, c.entry.Port, opts)
+	if err != nil {
+		return fmt.Errorf("Unable to create game server connection: %v", err)
+	}
+	c.mutex.Lock()
+	c.server = server
+	c.mutex.Unlock()
+	loginReq := request.Login{c.entry.UserID, c.entry.UserPass}
+	err = server.SendContext(ctx, request.Request{Login: []request.Login{loginReq}})
+	if err != nil {
+		server.Close()
+		return fmt.Errorf("Unable to send login request: %v", err)
+	}
+	return nil
+}
+
+// update updates the AI until the server connection is closed
+// or specified context is done.
+func (c *Client) update(ctx context.Context) {
+	err := ai.Loop(ctx, func(delta int64) bool {
+		if c.server.Closed() {
+			return false
+		}
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		if c.ai != nil {
+			c.ai.Update(delta)
+			c.ai.Game().Update(delta)
+		}
+		return true
+	})
+	if err != nil {
+		err := c.server.Close()
+		if err != nil {
+			c.logger.Error("unable to close server connection", "error", err)
+		}
+		return
+	}
+	c.logger.Info("server connection closed")
+}
+
+// disconnect saves the AI state and reputation and removes the AI
+// of the closed connection.
+// Saved state is restored for characters after reconnect.
+func (c *Client) disconnect() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ai == nil {
+		return
+	}
+	c.state = c.ai.Game().State()
+	c.ai = nil
+	if len(c.conf.StatePath) > 0 {
+		err := c.state.Save(c.path(c.conf.StatePath))
+		if err != nil {
+			c.logger.Error("unable to save AI state", "error", err)
+		}
+	}
+	if len(c.conf.ReputationPath) > 0 && c.reputation.Changed() {
+		err := c.reputation.Save(c.path(c.conf.ReputationPath))
+		if err != nil {
+			c.logger.Error("unable to save reputation", "error", err)
+		}
+	}
+}
+
+// handleResponse handles response from the server.
+func (c *Client) handleResponse(resp response.Response) {
+	if !resp.Logon {
+		c.handleUpdateResponse(resp.Update)
+		for _, r := range resp.Character {
+			c.handleCharacterResponse(r)
+		}
+	}
+	for _, r := range resp.Error {
+		c.logger.Warn("server error response", "message", r)
+	}
+}
+
+// handleUpdateResponse handles update response from the server.
+func (c *Client) handleUpdateResponse(resp response.Update) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ai != nil {
+		return
+	}
+	if c.clearRes {
+		res.Clear()
+	}
+	mod := flame.NewModule(resp.Module)
+	game := ai.NewGame(mod, c.conf)
+	game.SetLogger(c.logger)
+	game.SetClearResources(c.clearRes)
+	for _, p := range c.profiles {
+		game.AddProfile(p)
+	}
+	// Groups keep their members, so each game needs own groups.
+	groups, err := ai.ImportGroupsDir(c.conf.ProfilesPath)
+	if err != nil {
+		c.logger.Error("unable to import NPC groups", "error", err)
+	}
+	for _, g := range groups {
+		game.AddGroup(g)
+	}
+	game.SetReputation(c.reputation)
+	game.SetMetrics(c.metrics)
+	if c.state != nil {
+		game.SetState(c.state)
+	}
+	if len(c.conf.TradeLogPath) > 0 {
+		tradeLog := ai.NewTradeLog(c.path(c.conf.TradeLogPath), c.conf.TradeLogSize,
+			c.conf.TradeLogBackups)
+		game.SetTradeLog(tradeLog)
+	}
+	game.SetServer(c.server)
+	c.ai = ai.New(game)
+	c.ai.SetReputationPath(c.path(c.conf.ReputationPath))
+	c.ai.SetStatePath(c.path(c.conf.StatePath))
+	if c.trace != nil {
+		c.ai.SetTrace(c.trace)
+	}
+}
+
+// handleCharacterResponse handles character response from the server.
+func (c *Client) handleCharacterResponse(resp response.Character) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.ai == nil {
+		return
+	}
+	game := c.ai.Game()
+	for _, char := range game.Characters() {
+		if char.ID() == resp.ID && char.Serial() == resp.Serial {
+			return
+		}
+	}
+	for _, char := range game.Chapter().Characters() {
+		if resp.ID == char.ID() && resp.Serial == char.Serial() {
+			aiChar := ai.NewCharacter(char, game)
+			game.AddCharacter(aiChar)
+		}
+	}
+}
+
+// path returns path to the client file based on the specified path,
+// file name is suffixed with the client name to keep separate files
+// for each client.
+func (c *Client) path(path string) string {
+	return c.entry.Path(path)
+}
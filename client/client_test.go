@@ -0,0 +1,68 @@
+/*
+ * client_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isangeles/ignite/config"
+)
+
+// TestClientPath tests client file paths.
+func TestClientPath(t *testing.T) {
+	c := Client{}
+	if p := c.path("state.json"); p != "state.json" {
+		t.Errorf("Invalid path for unnamed client: %s", p)
+	}
+	c.entry.Name = "town"
+	if p := c.path("state.json"); p != "state_town.json" {
+		t.Errorf("Invalid path for named client: %s", p)
+	}
+	if p := c.path(""); p != "" {
+		t.Errorf("Invalid empty path: %s", p)
+	}
+}
+
+// TestClientRun tests running client with done context.
+func TestClientRun(t *testing.T) {
+	dir := t.TempDir()
+	conf := config.Default()
+	conf.ReputationPath = dir + "/reputation.json"
+	conf.StatePath = dir + "/state.json"
+	entry := config.Client{Name: "test", Host: "127.0.0.1", Port: "1"}
+	c, err := New(conf, entry, nil)
+	if err != nil {
+		t.Fatalf("Unable to create client: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.Run(ctx)
+	if err != nil {
+		t.Errorf("Run error: %v", err)
+	}
+	if c.AI() != nil {
+		t.Errorf("AI created without server connection")
+	}
+}
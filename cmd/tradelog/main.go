@@ -0,0 +1,129 @@
+/*
+ * main.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+// tradelog command prints summary of trades from the AI trade log.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/isangeles/ignite/ai"
+	"github.com/isangeles/ignite/config"
+)
+
+var (
+	logPath = flag.String("log", "", "path to the trade log file, trade logs of all clients from the config by default")
+	client  = flag.String("client", "", "name of the client with trade log from the config, all clients by default")
+	from    = flag.String("from", "", "start of the time range(RFC3339 or YYYY-MM-DD)")
+	to      = flag.String("to", "", "end of the time range(RFC3339 or YYYY-MM-DD)")
+)
+
+// Main function.
+func main() {
+	flag.Parse()
+	paths := make([]string, 0)
+	if len(*logPath) > 0 {
+		paths = append(paths, *logPath)
+	} else {
+		conf, err := config.Load()
+		if err != nil {
+			log.Printf("Unable to load config: %v", err)
+		}
+		if conf != nil && len(conf.TradeLogPath) > 0 {
+			paths = clientPaths(conf, *client)
+		}
+	}
+	if len(paths) < 1 {
+		log.Fatal("No trade log path specified")
+	}
+	fromTime, err := parseTime(*from)
+	if err != nil {
+		log.Fatalf("Invalid from time: %v", err)
+	}
+	toTime, err := parseTime(*to)
+	if err != nil {
+		log.Fatalf("Invalid to time: %v", err)
+	}
+	entries := make([]ai.TradeLogEntry, 0)
+	for _, p := range paths {
+		pathEntries, err := ai.ReadTradeLog(p)
+		if err != nil {
+			log.Fatalf("Unable to read trade log: %s: %v", p, err)
+		}
+		entries = append(entries, pathEntries...)
+	}
+	merchants, players := ai.SummarizeTrades(entries, fromTime, toTime)
+	fmt.Fprintln(os.Stdout, "Merchants:")
+	printSummary(merchants)
+	fmt.Fprintln(os.Stdout, "Players:")
+	printSummary(players)
+}
+
+// clientPaths returns trade log paths of the client with specified
+// name from specified configuration, or of all clients if the name
+// is empty.
+func clientPaths(conf *config.Config, name string) []string {
+	paths := make([]string, 0)
+	for _, e := range conf.Entries() {
+		if len(name) > 0 && e.Name != name {
+			continue
+		}
+		paths = append(paths, e.Path(conf.TradeLogPath))
+	}
+	if len(paths) < 1 {
+		log.Fatalf("Client not found: %s", name)
+	}
+	return paths
+}
+
+// printSummary prints specified trade summaries.
+func printSummary(summaries map[string]*ai.TradeSummary) {
+	keys := make([]string, 0)
+	for k := range summaries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := summaries[k]
+		fmt.Fprintf(os.Stdout, "%s: trades: %d accepted: %d rejected: %d buy value: %d sell value: %d\n",
+			k, s.Trades, s.Accepted, s.Rejected, s.BuyValue, s.SellValue)
+	}
+}
+
+// parseTime parses specified RFC3339 or date text.
+// Returns zero time for empty text.
+func parseTime(text string) (time.Time, error) {
+	if len(text) < 1 {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", text)
+}
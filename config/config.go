@@ -26,8 +26,14 @@ package config
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/isangeles/flame/data/text"
 )
@@ -37,60 +43,340 @@ const (
 	ConfigFileName = ".ignite"
 )
 
-var (
+// Struct for server and user entry of AI client.
+type Client struct {
+	Name     string
+	Host     string
+	Port     string
+	TLS      bool
+	UserID   string
+	UserPass string
+}
+
+// Struct for AI configuration.
+type Config struct {
 	// Server.
-	ServerHost = ""
-	ServerPort = ""
-	ServerTLS  = false
-	UserID     = ""
-	UserPass   = ""
-	// Random actions frequences(in millis).
-	MoveFreq int64 = 3000
-	ChatFreq int64 = 5000
-	DeaggroDis = 500.0
-)
+	ServerHost string
+	ServerPort string
+	ServerTLS  bool
+	UserID     string
+	UserPass   string
+	// Server and user entries.
+	Clients []Client
+	// Delay between reconnect attempts(in millis).
+	ReconnectDelay int64
+	// Timeout for connecting to the server(in millis).
+	ConnectTimeout int64
+	// NPC profiles.
+	ProfilesPath string
+	// Trade log.
+	TradeLogPath    string
+	TradeLogSize    int64
+	TradeLogBackups int
+	// Reputation.
+	ReputationPath     string
+	ReputationSaveFreq int64
+	ReputationAttack   int
+	ReputationKill     int
+	ReputationTrade    int
+	// AI state.
+	StatePath     string
+	StateSaveFreq int64
+	// Decision trace file and IDs of traced characters,
+	// all characters are traced if there are no IDs.
+	TracePath   string
+	TraceFilter []string
+	// Server command for adding items to merchant inventory.
+	RestockCommand string
+	// Frequency of checking configuration and profile files
+	// for changes(in millis), no checking if zero.
+	WatchFreq int64
+	// Address of HTTP listener for metrics, no
+	// listener if empty.
+	MetricsAddress string
+	// Logging level and format.
+	LogLevel  string
+	LogFormat string
+	// Seed for AI random decisions, random seed if zero.
+	Seed int64
+	// AI tuning values.
+	tuning atomic.Pointer[Tuning]
+	// Values loaded from the configuration file and
+	// environment variables, without later overrides.
+	loaded map[string][]string
+}
+
+// Default creates new configuration with default values.
+func Default() *Config {
+	c := Config{
+		ReconnectDelay:     5000,
+		ConnectTimeout:     10000,
+		ProfilesPath:       "profiles",
+		TradeLogSize:       10485760,
+		TradeLogBackups:    5,
+		ReputationPath:     "reputation.json",
+		ReputationSaveFreq: 60000,
+		ReputationAttack:   -50,
+		ReputationKill:     25,
+		ReputationTrade:    1,
+		StatePath:          "state.json",
+		StateSaveFreq:      30000,
+		RestockCommand:     "charman -o add -t {id}#{serial} -a item {item}",
+		LogLevel:           "info",
+		LogFormat:          "text",
+	}
+	c.SetTuning(Tuning{
+		MoveFreq:   3000,
+		ChatFreq:   5000,
+		DeaggroDis: 500.0,
+	})
+	return &c
+}
 
 // Load load server configuration file.
-func Load() error {
-	// Open config file.
-	file, err := os.Open(ConfigFileName)
+func Load() (*Config, error) {
+	return LoadFile(ConfigFileName)
+}
+
+// LoadFile loads configuration with default values overridden
+// by values from the configuration file with specified path and
+// environment variables.
+// Returns configuration with all valid values and error with all
+// invalid values.
+func LoadFile(path string) (*Config, error) {
+	conf, err := readFile(path)
 	if err != nil {
-		return fmt.Errorf("Unale to open config file: %v", err)
+		return nil, err
 	}
-	defer file.Close()
-	// Unmarshal config.
-	conf, err := text.UnmarshalConfig(file)
+	for k, v := range envValues() {
+		conf[k] = v
+	}
+	c := Default()
+	return c, c.apply(conf)
+}
+
+// LoadEnv loads configuration with default values overridden
+// by values from environment variables.
+func LoadEnv() (*Config, error) {
+	c := Default()
+	return c, c.apply(envValues())
+}
+
+// Unmarshal parses configuration values in the configuration
+// file format.
+func Unmarshal(r io.Reader) (*Config, error) {
+	conf, err := text.UnmarshalConfig(r)
 	if err != nil {
-		return fmt.Errorf("Unable to unmarshal config: %v", err)
+		return nil, fmt.Errorf("Unable to unmarshal config: %v", err)
 	}
-	if len(conf["server"]) > 1 {
-		ServerHost = conf["server"][0]
-		ServerPort = conf["server"][1]
+	if conf == nil {
+		conf = make(map[string][]string)
 	}
-	if len(conf["server-tls"]) > 1 {
-		ServerTLS = conf["server-tls"][0] == "true"
+	c := Default()
+	return c, c.apply(conf)
+}
+
+// Marshal returns configuration values in the configuration
+// file format.
+func (c *Config) Marshal() string {
+	return MarshalValues(c.Values())
+}
+
+// apply sets configuration values from specified config values.
+// Returns error with all invalid values, valid values are set
+// anyway.
+func (c *Config) apply(conf map[string][]string) error {
+	v := validator{conf: conf}
+	v.checkKeys()
+	if values := v.values("server", 2, 2); values != nil {
+		c.ServerHost = values[0]
+		c.ServerPort = values[1]
 	}
-	if len(conf["user"]) > 1 {
-		UserID = conf["user"][0]
-		UserPass = conf["user"][1]
+	if v.values("server-tls", 1, 1) != nil {
+		v.boolValue("server-tls", 0, &c.ServerTLS)
 	}
-	if len(conf["move-freq"]) > 0 {
-		moveFreq, err := strconv.ParseInt(conf["move-freq"][0], 0, 64)
-		if err == nil {
-			MoveFreq = moveFreq
+	if values := v.values("user", 1, 2); values != nil {
+		c.UserID = values[0]
+		if len(values) > 1 {
+			c.UserPass = values[1]
 		}
 	}
-	if len(conf["chat-freq"]) > 0 {
-		chatFreq, err := strconv.ParseInt(conf["chat-freq"][0], 0, 64)
-		if err == nil {
-			ChatFreq = chatFreq
+	if values := v.values("user-pass-file", 1, 1); values != nil {
+		pass, err := ReadSecret(values[0])
+		if err != nil {
+			v.errorf("user-pass-file", "unable to read password: %v", err)
+		} else {
+			c.UserPass = pass
 		}
 	}
-	if len(conf["deaggro-dis"]) > 0 {
-		deaggroDis, err := strconv.ParseFloat(conf["deaggro-dis"][0], 64)
-		if err == nil {
-			DeaggroDis = deaggroDis
+	c.Clients = nil
+	values := v.values("clients", 6, -1)
+	if len(values)%6 != 0 {
+		v.errorf("clients", "invalid number of values: %d, expected 6 for each client",
+			len(values))
+		values = nil
+	}
+	for i := 0; i < len(values); i += 6 {
+		cl := Client{
+			Name:     values[i],
+			Host:     values[i+1],
+			Port:     values[i+2],
+			UserID:   values[i+4],
+			UserPass: values[i+5],
 		}
+		v.boolValue("clients", i+3, &cl.TLS)
+		c.Clients = append(c.Clients, cl)
+	}
+	if v.values("reconnect-delay", 1, 1) != nil {
+		v.int64Value("reconnect-delay", 0, 0, &c.ReconnectDelay)
+	}
+	if v.values("connect-timeout", 1, 1) != nil {
+		v.int64Value("connect-timeout", 0, 1, &c.ConnectTimeout)
+	}
+	if values := v.values("profiles", 1, 1); values != nil {
+		c.ProfilesPath = values[0]
+	}
+	if values := v.values("trade-log", 1, 3); values != nil {
+		c.TradeLogPath = values[0]
+		v.int64Value("trade-log", 1, 1, &c.TradeLogSize)
+		v.intValue("trade-log", 2, 0, &c.TradeLogBackups)
+	}
+	if values := v.values("reputation", 1, 2); values != nil {
+		c.ReputationPath = values[0]
+		v.int64Value("reputation", 1, 1, &c.ReputationSaveFreq)
+	}
+	if v.values("reputation-changes", 3, 3) != nil {
+		v.intValue("reputation-changes", 0, math.MinInt, &c.ReputationAttack)
+		v.intValue("reputation-changes", 1, math.MinInt, &c.ReputationKill)
+		v.intValue("reputation-changes", 2, math.MinInt, &c.ReputationTrade)
+	}
+	if values := v.values("state", 1, 2); values != nil {
+		c.StatePath = values[0]
+		v.int64Value("state", 1, 1, &c.StateSaveFreq)
+	}
+	if values := v.values("trace", 1, -1); values != nil {
+		c.TracePath = values[0]
+		c.TraceFilter = values[1:]
+	}
+	if values := v.values("restock-cmd", 1, 1); values != nil {
+		c.RestockCommand = values[0]
+	}
+	if v.values("watch-freq", 1, 1) != nil {
+		v.int64Value("watch-freq", 0, 0, &c.WatchFreq)
+	}
+	if values := v.values("metrics", 1, 1); values != nil {
+		c.MetricsAddress = values[0]
+	}
+	if values := v.values("log-level", 1, 1); values != nil {
+		c.LogLevel = values[0]
+	}
+	if values := v.values("log-format", 1, 1); values != nil {
+		c.LogFormat = values[0]
+	}
+	if v.values("seed", 1, 1) != nil {
+		v.int64Value("seed", 0, math.MinInt64, &c.Seed)
+	}
+	t := c.Tuning()
+	v.tuning(&t)
+	c.SetTuning(t)
+	c.loaded = c.Values()
+	return v.err()
+}
+
+// Path returns path to the client file based on specified path,
+// file name is suffixed with the client name to keep separate
+// files for each named client, e.g. 'trades.log' is 'trades_town.log'
+// for 'town' client.
+func (c Client) Path(path string) string {
+	if len(path) < 1 || len(c.Name) < 1 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s_%s%s", strings.TrimSuffix(path, ext), c.Name, ext)
+}
+
+// Entries returns server and user entries for AI clients, or
+// single entry with server and user values if there are no
+// client entries.
+func (c *Config) Entries() []Client {
+	if len(c.Clients) > 0 {
+		return c.Clients
+	}
+	cl := Client{
+		Host:     c.ServerHost,
+		Port:     c.ServerPort,
+		TLS:      c.ServerTLS,
+		UserID:   c.UserID,
+		UserPass: c.UserPass,
+	}
+	return []Client{cl}
+}
+
+// Values returns current configuration values for
+// configuration keys.
+func (c *Config) Values() map[string][]string {
+	values := make(map[string][]string)
+	values["server"] = []string{c.ServerHost, c.ServerPort}
+	values["server-tls"] = []string{strconv.FormatBool(c.ServerTLS)}
+	values["user"] = []string{c.UserID, c.UserPass}
+	for _, cl := range c.Clients {
+		values["clients"] = append(values["clients"], cl.Name, cl.Host, cl.Port,
+			strconv.FormatBool(cl.TLS), cl.UserID, cl.UserPass)
+	}
+	values["connect-timeout"] = []string{strconv.FormatInt(c.ConnectTimeout, 10)}
+	values["reconnect-delay"] = []string{strconv.FormatInt(c.ReconnectDelay, 10)}
+	values["profiles"] = []string{c.ProfilesPath}
+	values["trade-log"] = []string{c.TradeLogPath, strconv.FormatInt(c.TradeLogSize, 10),
+		strconv.Itoa(c.TradeLogBackups)}
+	values["reputation"] = []string{c.ReputationPath, strconv.FormatInt(c.ReputationSaveFreq, 10)}
+	values["reputation-changes"] = []string{strconv.Itoa(c.ReputationAttack),
+		strconv.Itoa(c.ReputationKill), strconv.Itoa(c.ReputationTrade)}
+	values["state"] = []string{c.StatePath, strconv.FormatInt(c.StateSaveFreq, 10)}
+	values["trace"] = append([]string{c.TracePath}, c.TraceFilter...)
+	values["restock-cmd"] = []string{c.RestockCommand}
+	values["watch-freq"] = []string{strconv.FormatInt(c.WatchFreq, 10)}
+	values["metrics"] = []string{c.MetricsAddress}
+	values["log-level"] = []string{c.LogLevel}
+	values["log-format"] = []string{c.LogFormat}
+	values["seed"] = []string{strconv.FormatInt(c.Seed, 10)}
+	t := c.Tuning()
+	values["move-freq"] = []string{strconv.FormatInt(t.MoveFreq, 10)}
+	values["chat-freq"] = []string{strconv.FormatInt(t.ChatFreq, 10)}
+	values["deaggro-dis"] = []string{strconv.FormatFloat(t.DeaggroDis, 'f', -1, 64)}
+	return values
+}
+
+// MarshalValues returns specified configuration values in the
+// configuration file format, sorted by keys.
+func MarshalValues(values map[string][]string) string {
+	keys := make([]string, 0)
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	text := ""
+	for _, k := range keys {
+		text += fmt.Sprintf("%s:%s\n", k, strings.Join(values[k], ";"))
+	}
+	return text
+}
+
+// readFile reads configuration values from the file with
+// specified path.
+func readFile(path string) (map[string][]string, error) {
+	// Open config file.
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unale to open config file: %v", err)
+	}
+	defer file.Close()
+	// Unmarshal config.
+	conf, err := text.UnmarshalConfig(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to unmarshal config: %v", err)
+	}
+	if conf == nil {
+		conf = make(map[string][]string)
 	}
-	return nil
+	return conf, nil
 }
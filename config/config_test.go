@@ -0,0 +1,49 @@
+/*
+ * config_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMarshal tests marshaling and unmarshaling configuration.
+func TestMarshal(t *testing.T) {
+	conf := Default()
+	conf.ServerHost, conf.ServerPort, conf.UserID = "localhost", "8000", "ai"
+	conf.Clients = []Client{{"town", "localhost", "8000", true, "ai-town", "pass"}}
+	conf.ReputationAttack = -10
+	conf.SetTuning(Tuning{MoveFreq: 100, ChatFreq: 200, DeaggroDis: 50.5})
+	text := conf.Marshal()
+	if !strings.Contains(text, "move-freq:100\n") {
+		t.Errorf("Invalid marshaled config: %s", text)
+	}
+	unmarshaled, err := Unmarshal(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Unable to unmarshal config: %v", err)
+	}
+	if unmarshaled.Marshal() != text {
+		t.Errorf("Invalid unmarshaled config: %s", unmarshaled.Marshal())
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * env.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Prefix of environment variables with configuration values.
+const EnvPrefix = "IGNITE_"
+
+// envValues returns configuration values from environment variables.
+// Variable name without prefix is converted to the configuration key,
+// e.g. IGNITE_MOVE_FREQ is used as move-freq value.
+// Variables with unknown keys are ignored with a warning, since the
+// prefix could be used by other variables, e.g. IGNITE_HOME.
+func envValues() map[string][]string {
+	values := make(map[string][]string)
+	for _, env := range os.Environ() {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key := strings.TrimPrefix(name, EnvPrefix)
+		key = strings.ReplaceAll(strings.ToLower(key), "_", "-")
+		if !contains(keys, key) {
+			if s := suggestKey(key); len(s) > 0 {
+				slog.Warn("unknown configuration variable ignored", "variable", name,
+					"suggestion", s)
+				continue
+			}
+			slog.Warn("unknown configuration variable ignored", "variable", name)
+			continue
+		}
+		values[key] = strings.Split(value, ";")
+	}
+	return values
+}
+
+// ReadSecret reads secret value from the file with specified path.
+// Logs warning if the file is readable by other users.
+func ReadSecret(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("Unable to check secret file: %v", err)
+	}
+	if info.Mode().Perm()&0004 != 0 {
+		slog.Warn("secret file is readable by all users", "path", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Unable to read secret file: %v", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,57 @@
+/*
+ * env_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadEnv tests loading configuration from environment variables.
+func TestLoadEnv(t *testing.T) {
+	passPath := filepath.Join(t.TempDir(), "pass")
+	err := os.WriteFile(passPath, []byte("secret\n"), 0600)
+	if err != nil {
+		t.Fatalf("Unable to write password file: %v", err)
+	}
+	t.Setenv("IGNITE_SERVER", "example;8000")
+	t.Setenv("IGNITE_USER", "ai")
+	t.Setenv("IGNITE_USER_PASS_FILE", passPath)
+	t.Setenv("IGNITE_MOVE_FREQ", "100")
+	t.Setenv("IGNITE_HOME", "/opt/ignite")
+	c, err := LoadEnv()
+	if err != nil {
+		t.Fatalf("Unable to load config: %v", err)
+	}
+	if c.ServerHost != "example" || c.ServerPort != "8000" {
+		t.Errorf("Invalid server: %s %s", c.ServerHost, c.ServerPort)
+	}
+	if c.UserID != "ai" || c.UserPass != "secret" {
+		t.Errorf("Invalid user: %s %s", c.UserID, c.UserPass)
+	}
+	if c.Tuning().MoveFreq != 100 {
+		t.Errorf("Invalid move frequency: %d", c.Tuning().MoveFreq)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * log_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestNewLogger tests creating logger with level and format
+// from the configuration.
+func TestNewLogger(t *testing.T) {
+	conf := Default()
+	conf.LogLevel = "warn"
+	conf.LogFormat = "json"
+	buf := new(bytes.Buffer)
+	logger := conf.NewLogger(buf)
+	logger.Info("info message")
+	logger.Warn("warn message", "char-id", "wolf")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Invalid number of log lines: %d != 1", len(lines))
+	}
+	var entry map[string]string
+	err := json.Unmarshal([]byte(lines[0]), &entry)
+	if err != nil {
+		t.Fatalf("Unable to unmarshal log line: %v", err)
+	}
+	if entry["msg"] != "warn message" || entry["char-id"] != "wolf" {
+		t.Errorf("Invalid log entry: %v", entry)
+	}
+}
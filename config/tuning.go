@@ -0,0 +1,123 @@
+/*
+ * tuning.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// Struct for AI tuning values, which could be reloaded
+// while the AI is running.
+type Tuning struct {
+	// Random actions frequences(in millis).
+	MoveFreq   int64
+	ChatFreq   int64
+	DeaggroDis float64
+}
+
+// Keys of AI tuning values.
+var tuningKeys = []string{"move-freq", "chat-freq", "deaggro-dis"}
+
+// Tuning returns current AI tuning values.
+func (c *Config) Tuning() Tuning {
+	t := c.tuning.Load()
+	if t == nil {
+		return Tuning{}
+	}
+	return *t
+}
+
+// SetTuning sets AI tuning values.
+func (c *Config) SetTuning(t Tuning) {
+	c.tuning.Store(&t)
+}
+
+// Reload loads configuration file with specified path and
+// environment variables, and sets new AI tuning values.
+// Tuning values are set only if all of them are valid, other
+// values are not changed.
+// Returns keys of values which require restart, i.e. values
+// different from the values loaded on startup, so values set
+// by flags are not reported.
+func (c *Config) Reload(path string) ([]string, error) {
+	conf, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range envValues() {
+		conf[k] = v
+	}
+	v := validator{conf: conf}
+	v.checkKeys()
+	t := c.Tuning()
+	v.tuning(&t)
+	err = v.err()
+	if err != nil {
+		return nil, err
+	}
+	current := c.loaded
+	if current == nil {
+		current = c.Values()
+	}
+	restart := make([]string, 0)
+	for k, values := range conf {
+		if contains(tuningKeys, k) {
+			continue
+		}
+		cur, ok := current[k]
+		if !ok {
+			continue
+		}
+		if len(values) > len(cur) || strings.Join(values, ";") != strings.Join(cur[:len(values)], ";") {
+			restart = append(restart, k)
+		}
+	}
+	sort.Strings(restart)
+	c.SetTuning(t)
+	return restart, nil
+}
+
+// tuning parses AI tuning values and sets them to specified tuning.
+func (v *validator) tuning(t *Tuning) {
+	if v.values("move-freq", 1, 1) != nil {
+		v.int64Value("move-freq", 0, 1, &t.MoveFreq)
+	}
+	if v.values("chat-freq", 1, 1) != nil {
+		v.int64Value("chat-freq", 0, 1, &t.ChatFreq)
+	}
+	if v.values("deaggro-dis", 1, 1) != nil {
+		v.floatValue("deaggro-dis", 0, 0, &t.DeaggroDis)
+	}
+}
+
+// contains checks if specified slice contains specified text.
+func contains(s []string, text string) bool {
+	for _, t := range s {
+		if t == text {
+			return true
+		}
+	}
+	return false
+}
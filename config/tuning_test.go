@@ -0,0 +1,88 @@
+/*
+ * tuning_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestReload tests reloading AI tuning values.
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ignite")
+	err := os.WriteFile(path, []byte{}, 0600)
+	if err != nil {
+		t.Fatalf("Unable to write config file: %v", err)
+	}
+	c := Default()
+	c.ServerHost, c.ServerPort = "localhost", "8000"
+	t.Setenv("IGNITE_SERVER", "example;8000")
+	t.Setenv("IGNITE_MOVE_FREQ", "1234")
+	restart, err := c.Reload(path)
+	if err != nil {
+		t.Fatalf("Unable to reload config: %v", err)
+	}
+	if c.Tuning().MoveFreq != 1234 {
+		t.Errorf("Invalid move frequency: %d", c.Tuning().MoveFreq)
+	}
+	if len(restart) != 1 || restart[0] != "server" {
+		t.Errorf("Invalid values requiring restart: %v", restart)
+	}
+	if c.ServerHost != "localhost" {
+		t.Errorf("Server host changed on reload: %s", c.ServerHost)
+	}
+	// Invalid values.
+	t.Setenv("IGNITE_MOVE_FREQ", "100")
+	t.Setenv("IGNITE_CHAT_FREQ", "-1")
+	_, err = c.Reload(path)
+	if err == nil {
+		t.Errorf("No error for invalid values")
+	}
+	if c.Tuning().MoveFreq != 1234 {
+		t.Errorf("Tuning changed by invalid config: %d", c.Tuning().MoveFreq)
+	}
+}
+
+// TestReloadOverride tests reloading configuration with values
+// overridden after load.
+func TestReloadOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ignite")
+	err := os.WriteFile(path, []byte("server:localhost;8000\n"), 0600)
+	if err != nil {
+		t.Fatalf("Unable to write config file: %v", err)
+	}
+	c, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to load config: %v", err)
+	}
+	c.ServerHost, c.Seed = "example", 42
+	restart, err := c.Reload(path)
+	if err != nil {
+		t.Fatalf("Unable to reload config: %v", err)
+	}
+	if len(restart) > 0 {
+		t.Errorf("Overridden values reported as changed: %v", restart)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+ * validate.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Configuration keys.
+var keys = []string{
+	"server", "server-tls", "user", "user-pass-file", "clients",
+	"connect-timeout", "reconnect-delay", "profiles", "trade-log",
+	"reputation", "reputation-changes", "state", "trace", "restock-cmd",
+	"watch-freq", "metrics", "log-level", "log-format", "seed", "move-freq",
+	"chat-freq", "deaggro-dis",
+}
+
+// Struct for parsing and validating configuration values.
+type validator struct {
+	conf map[string][]string
+	errs []error
+}
+
+// errorf adds error for specified key.
+func (v *validator) errorf(key, format string, args ...interface{}) {
+	err := fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...))
+	v.errs = append(v.errs, err)
+}
+
+// values returns values for specified key if number of values
+// is in specified range, max below zero means no limit.
+// Returns nil if key is not set or number of values is invalid.
+func (v *validator) values(key string, min, max int) []string {
+	values, ok := v.conf[key]
+	if !ok {
+		return nil
+	}
+	if len(values) < min || (max >= 0 && len(values) > max) {
+		v.errorf(key, "invalid number of values: %d", len(values))
+		return nil
+	}
+	return values
+}
+
+// int64Value parses integer value with specified index for specified
+// key and sets it to specified destination if value is not below
+// specified minimum.
+func (v *validator) int64Value(key string, i int, min int64, dest *int64) {
+	values := v.conf[key]
+	if i >= len(values) {
+		return
+	}
+	value, err := strconv.ParseInt(values[i], 0, 64)
+	if err != nil {
+		v.errorf(key, "invalid integer value: '%s'", values[i])
+		return
+	}
+	if value < min {
+		v.errorf(key, "value '%s' below minimum: %d", values[i], min)
+		return
+	}
+	*dest = value
+}
+
+// intValue parses integer value with specified index for specified
+// key and sets it to specified destination if value is not below
+// specified minimum.
+func (v *validator) intValue(key string, i int, min int, dest *int) {
+	value := int64(*dest)
+	v.int64Value(key, i, int64(min), &value)
+	*dest = int(value)
+}
+
+// floatValue parses float value with specified index for specified
+// key and sets it to specified destination if value is not below
+// specified minimum.
+func (v *validator) floatValue(key string, i int, min float64, dest *float64) {
+	values := v.conf[key]
+	if i >= len(values) {
+		return
+	}
+	value, err := strconv.ParseFloat(values[i], 64)
+	if err != nil {
+		v.errorf(key, "invalid number value: '%s'", values[i])
+		return
+	}
+	if value < min {
+		v.errorf(key, "value '%s' below minimum: %g", values[i], min)
+		return
+	}
+	*dest = value
+}
+
+// boolValue parses boolean value with specified index for specified
+// key and sets it to specified destination.
+func (v *validator) boolValue(key string, i int, dest *bool) {
+	values := v.conf[key]
+	if i >= len(values) {
+		return
+	}
+	switch values[i] {
+	case "true":
+		*dest = true
+	case "false":
+		*dest = false
+	default:
+		v.errorf(key, "invalid boolean value: '%s', expected 'true' or 'false'", values[i])
+	}
+}
+
+// checkKeys adds errors for all unknown keys.
+func (v *validator) checkKeys() {
+	unknown := make([]string, 0)
+	for k := range v.conf {
+		if !contains(keys, k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		if s := suggestKey(k); len(s) > 0 {
+			v.errorf(k, "unknown key, did you mean '%s'?", s)
+			continue
+		}
+		v.errorf(k, "unknown key")
+	}
+}
+
+// err returns all validation errors joined in one error
+// or nil if there are no errors.
+func (v *validator) err() error {
+	return errors.Join(v.errs...)
+}
+
+// Validate checks if configuration values are valid for
+// connecting to the server.
+func (c *Config) Validate() error {
+	v := validator{}
+	for _, e := range c.Entries() {
+		key := "server"
+		if len(e.Name) > 0 {
+			key = "clients: " + e.Name
+		}
+		if len(e.Host) < 1 || len(e.Port) < 1 {
+			v.errorf(key, "server host and port required")
+		}
+		if len(e.UserID) < 1 {
+			v.errorf(key, "user ID required")
+		}
+	}
+	c.validateValues(&v)
+	return v.err()
+}
+
+// ValidateOffline checks if configuration values are valid for
+// running the AI without server connection.
+func (c *Config) ValidateOffline() error {
+	v := validator{}
+	c.validateValues(&v)
+	return v.err()
+}
+
+// validateValues checks configuration values not related to
+// the server connection.
+func (c *Config) validateValues(v *validator) {
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		v.errorf("log-level", "invalid level: '%s', expected 'debug', 'info', 'warn' or 'error'",
+			c.LogLevel)
+	}
+	switch c.LogFormat {
+	case "text", "json":
+	default:
+		v.errorf("log-format", "invalid format: '%s', expected 'text' or 'json'", c.LogFormat)
+	}
+}
+
+// suggestKey returns configuration key similar to specified
+// text or empty string if there is no similar key.
+func suggestKey(text string) string {
+	suggestion := ""
+	minDis := math.MaxInt
+	for _, k := range keys {
+		dis := editDistance(text, k)
+		if dis < minDis {
+			suggestion, minDis = k, dis
+		}
+	}
+	if minDis > 3 || minDis >= len(text) {
+		return ""
+	}
+	return suggestion
+}
+
+// editDistance returns Levenshtein distance between
+// specified texts.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,72 @@
+/*
+ * validate_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestLoadInvalid tests loading invalid configuration values.
+func TestLoadInvalid(t *testing.T) {
+	t.Setenv("IGNITE_SERVER", "localhost")
+	t.Setenv("IGNITE_MOVE_FREQ", "abc")
+	t.Setenv("IGNITE_CHAT_FREQ", "-5")
+	_, err := LoadEnv()
+	if err == nil {
+		t.Fatalf("No error for invalid config")
+	}
+	for _, e := range []string{
+		"server: invalid number of values",
+		"move-freq: invalid integer value: 'abc'",
+		"chat-freq: value '-5' below minimum",
+	} {
+		if !strings.Contains(err.Error(), e) {
+			t.Errorf("Error not reported: %s", e)
+		}
+	}
+}
+
+// TestValidate tests validation of configuration for connecting
+// to the server.
+func TestValidate(t *testing.T) {
+	c := Default()
+	if c.Validate() == nil {
+		t.Errorf("No error for missing server and user")
+	}
+	c.ServerHost, c.ServerPort, c.UserID = "localhost", "8000", "ai"
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validation error: %v", err)
+	}
+}
+
+// TestSuggestKey tests suggestions for unknown keys.
+func TestSuggestKey(t *testing.T) {
+	if s := suggestKey("move-frq"); s != "move-freq" {
+		t.Errorf("Invalid suggestion: %s", s)
+	}
+	if s := suggestKey("something"); len(s) > 0 {
+		t.Errorf("Suggestion for unrelated key: %s", s)
+	}
+}
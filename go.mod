@@ -1,6 +1,6 @@
 module github.com/isangeles/ignite
 
-go 1.20
+go 1.21
 
 require (
 	github.com/gorilla/websocket v1.5.3
@@ -25,100 +25,231 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"time"
-
-	"github.com/isangeles/flame"
-	"github.com/isangeles/flame/data/res"
-
-	"github.com/isangeles/fire/request"
-	"github.com/isangeles/fire/response"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/isangeles/ignite/ai"
+	"github.com/isangeles/ignite/client"
 	"github.com/isangeles/ignite/config"
 )
 
-var (
-	AI     *ai.AI
-	server *ai.Server
-)
+const usage = `Usage: ignite [command] [flags]
+
+Commands:
+  run           connect to the game server and run the AI(default)
+  check-config  validate and print the effective configuration
+  simulate      run the AI offline with the game module from the disk
+  version       print the program version
+
+Run 'ignite [command] -h' for command flags.
+`
+
+// Struct for command-line flags overriding configuration values.
+type flags struct {
+	set        *flag.FlagSet
+	configPath string
+	host       string
+	port       string
+	tls        bool
+	user       string
+	password   string
+	passFile   string
+	logLevel   string
+	logFormat  string
+	seed       int64
+}
 
 // Main function.
 func main() {
-	log.Printf("%s(%s)", config.Name, config.Version)
-	// Load config.
-	err := config.Load()
-	if err != nil {
-		panic(fmt.Errorf("Unable to load config: %v", err))
+	cmd, args := "run", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd, args = args[0], args[1:]
+	}
+	switch cmd {
+	case "run":
+		run(args)
+	case "check-config":
+		checkConfig(args)
+	case "simulate":
+		simulate(args)
+	case "version":
+		fmt.Printf("%s %s\n", config.Name, config.Version)
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n%s", cmd, usage)
+		os.Exit(2)
 	}
-	// Connect to the server.
-	server, err = ai.NewServer(config.ServerHost, config.ServerPort, config.ServerTLS)
+}
+
+// run connects to the game server and runs the AI.
+func run(args []string) {
+	f := newFlags("run", true)
+	f.set.Parse(args)
+	conf, err := f.load()
 	if err != nil {
-		panic(fmt.Errorf("Unable to create game server connection: %v",
-			err))
+		log.Fatalf("Invalid config:\n%v", err)
 	}
-	server.SetOnResponseFunc(handleResponse)
-	// Login to the server.
-	loginReq := request.Login{config.UserID, config.UserPass}
-	err = server.Send(request.Request{Login: []request.Login{loginReq}})
+	slog.SetDefault(conf.NewLogger(os.Stderr))
+	slog.Info("starting", "name", config.Name, "version", config.Version)
+	// Import NPC profiles.
+	profiles, err := importProfiles(conf.ProfilesPath)
 	if err != nil {
-		panic(fmt.Errorf("Unable to send login request: %v", err))
+		slog.Error("unable to import NPC profiles", "error", err)
 	}
-	update := time.Now()
-	for !server.Closed() {
-		// Update.
-		delta := time.Since(update).Milliseconds()
-		if AI == nil {
-			continue
+	// Run clients.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	entries := conf.Entries()
+	clients := make([]*client.Client, 0)
+	metrics := make([]*ai.Metrics, 0)
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		c, err := client.New(conf, e, profiles)
+		if err != nil {
+			log.Fatalf("Unable to create client: %s: %v", e.Name, err)
+		}
+		c.SetClearResources(len(entries) < 2)
+		if len(conf.MetricsAddress) > 0 {
+			m := ai.NewMetrics(e.Name)
+			c.SetMetrics(m)
+			metrics = append(metrics, m)
 		}
-		AI.Update(delta)
-		AI.Game().Update(delta)
-		update = time.Now()
-		// Update break.
-		time.Sleep(time.Duration(16) * time.Millisecond)
+		clients = append(clients, c)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Run(ctx)
+		}()
 	}
+	go watchConfig(ctx, conf, f.configPath, clients)
+	if len(conf.MetricsAddress) > 0 {
+		go serveMetrics(ctx, conf.MetricsAddress, metrics)
+	}
+	wg.Wait()
 }
 
-// handleResponse handles response from the server.
-func handleResponse(resp response.Response) {
-	if !resp.Logon {
-		handleUpdateResponse(resp.Update)
-		for _, r := range resp.Character {
-			handleCharacterResponse(r)
-		}
+// checkConfig loads configuration and prints effective
+// configuration values, with passwords hidden.
+func checkConfig(args []string) {
+	f := newFlags("check-config", true)
+	f.set.Parse(args)
+	conf, err := f.load()
+	if err != nil {
+		log.Fatalf("Invalid config:\n%v", err)
 	}
-	for _, r := range resp.Error {
-		log.Printf("Server error response: %s", r)
+	values := conf.Values()
+	values["user"][1] = hidden(values["user"][1])
+	for i := 5; i < len(values["clients"]); i += 6 {
+		values["clients"][i] = hidden(values["clients"][i])
 	}
+	fmt.Print(config.MarshalValues(values))
 }
 
-// handleUpdateResponse handles update response from the server.
-func handleUpdateResponse(resp response.Update) {
-	if AI != nil {
-		return
+// newFlags creates flags for command with specified name,
+// server flags are added only if server is true.
+func newFlags(name string, server bool) *flags {
+	f := flags{set: flag.NewFlagSet(name, flag.ExitOnError)}
+	f.set.StringVar(&f.configPath, "config", config.ConfigFileName, "path to the configuration file")
+	if server {
+		f.set.StringVar(&f.host, "host", "", "game server host")
+		f.set.StringVar(&f.port, "port", "", "game server port")
+		f.set.BoolVar(&f.tls, "tls", false, "use TLS for server connection")
+		f.set.StringVar(&f.user, "user", "", "AI user ID")
+		f.set.StringVar(&f.password, "password", "", "AI user password")
+		f.set.StringVar(&f.passFile, "password-file", "", "path to the file with AI user password")
 	}
-	res.Clear()
-	mod := flame.NewModule(resp.Module)
-	game := ai.NewGame(mod)
-	game.SetServer(server)
-	AI = ai.New(game)
+	f.set.StringVar(&f.logLevel, "log-level", "", "logging level(debug, info, warn or error)")
+	f.set.StringVar(&f.logFormat, "log-format", "", "logging format(text or json)")
+	f.set.Int64Var(&f.seed, "seed", 0, "seed for AI random decisions, random if 0")
+	return &f
 }
 
-// handleCharacterResponse handles character response from the server.
-func handleCharacterResponse(resp response.Character) {
-	if AI == nil {
-		return
+// load loads configuration, overrides configuration values
+// with flags set by the user and validates configuration.
+func (f *flags) load() (*config.Config, error) {
+	conf, err := f.read()
+	return conf, errors.Join(err, conf.Validate())
+}
+
+// read loads configuration file and overrides configuration
+// values with flags set by the user.
+// Default configuration file is optional, default configuration
+// values and environment variables are used if the file doesn't
+// exist.
+func (f *flags) read() (*config.Config, error) {
+	var conf *config.Config
+	var loadErr error
+	_, err := os.Stat(f.configPath)
+	if f.configPath == config.ConfigFileName && os.IsNotExist(err) {
+		conf, loadErr = config.LoadEnv()
+	} else {
+		conf, loadErr = config.LoadFile(f.configPath)
 	}
-	for _, c := range AI.Game().Characters() {
-		if c.ID() == resp.ID && c.Serial() == resp.Serial {
-			return
-		}
+	if conf == nil {
+		conf = config.Default()
 	}
-	for _, c := range AI.Game().Chapter().Characters() {
-		if resp.ID == c.ID() && resp.Serial == c.Serial() {
-			aiChar := ai.NewCharacter(c, AI.Game())
-			AI.Game().AddCharacter(aiChar)
+	f.set.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "host":
+			conf.ServerHost = f.host
+		case "port":
+			conf.ServerPort = f.port
+		case "tls":
+			conf.ServerTLS = f.tls
+		case "user":
+			conf.UserID = f.user
+		case "password":
+			conf.UserPass = f.password
+		case "password-file":
+			pass, err := config.ReadSecret(f.passFile)
+			if err != nil {
+				log.Fatalf("Unable to read user password: %v", err)
+			}
+			conf.UserPass = pass
+		case "log-level":
+			conf.LogLevel = f.logLevel
+		case "log-format":
+			conf.LogFormat = f.logFormat
+		case "seed":
+			conf.Seed = f.seed
 		}
+	})
+	return conf, loadErr
+}
+
+// serveMetrics serves specified metrics in Prometheus text format
+// on the HTTP listener with specified address, until specified
+// context is done.
+func serveMetrics(ctx context.Context, addr string, metrics []*ai.Metrics) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", ai.MetricsHandler(metrics...))
+	server := http.Server{Addr: addr, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	slog.Info("serving metrics", "address", addr)
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("unable to serve metrics", "error", err)
+	}
+}
+
+// hidden returns replacement text for specified secret value.
+func hidden(value string) string {
+	if len(value) < 1 {
+		return value
 	}
+	return "***"
 }
@@ -0,0 +1,122 @@
+/*
+ * reload.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/isangeles/ignite/ai"
+	"github.com/isangeles/ignite/client"
+	"github.com/isangeles/ignite/config"
+)
+
+// watchConfig reloads specified configuration from the file with
+// specified path and NPC profiles for specified clients on SIGHUP
+// signal or after change of the configuration or profile files,
+// until specified context is done.
+func watchConfig(ctx context.Context, conf *config.Config, path string, clients []*client.Client) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+	var check <-chan time.Time
+	if conf.WatchFreq > 0 {
+		ticker := time.NewTicker(time.Duration(conf.WatchFreq) * time.Millisecond)
+		defer ticker.Stop()
+		check = ticker.C
+	}
+	modTime := filesModTime(path, conf.ProfilesPath)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			reload(conf, path, clients)
+			modTime = filesModTime(path, conf.ProfilesPath)
+		case <-check:
+			t := filesModTime(path, conf.ProfilesPath)
+			if t.Equal(modTime) {
+				continue
+			}
+			modTime = t
+			reload(conf, path, clients)
+		}
+	}
+}
+
+// reload reloads AI tuning values of specified configuration from
+// the configuration file with specified path and NPC profiles for
+// specified clients.
+func reload(conf *config.Config, path string, clients []*client.Client) {
+	restart, err := conf.Reload(path)
+	if err != nil {
+		slog.Error("unable to reload config", "error", err)
+		return
+	}
+	for _, k := range restart {
+		slog.Warn("config value changed, restart required", "key", k)
+	}
+	profiles, err := importProfiles(conf.ProfilesPath)
+	if err != nil {
+		slog.Error("unable to reload NPC profiles", "error", err)
+		return
+	}
+	for _, c := range clients {
+		c.SetProfiles(profiles)
+	}
+	slog.Info("config reloaded")
+}
+
+// importProfiles imports NPC profiles from the directory with
+// specified path, missing directory means no profiles.
+func importProfiles(path string) ([]*ai.Profile, error) {
+	_, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return ai.ImportProfilesDir(path)
+}
+
+// filesModTime returns the latest modification time of the file
+// with specified path, the directory with specified path and files
+// in the directory.
+// Directory modification time changes after removal of its files.
+func filesModTime(path, dirPath string) (modTime time.Time) {
+	paths, _ := filepath.Glob(filepath.Join(dirPath, "*"))
+	for _, p := range append(paths, path, dirPath) {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return
+}
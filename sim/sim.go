@@ -0,0 +1,208 @@
+/*
+ * sim.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+// sim package provides offline simulation of the AI with game
+// module loaded from the disk, without game server.
+package sim
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/area"
+	"github.com/isangeles/flame/character"
+	"github.com/isangeles/flame/data"
+	"github.com/isangeles/flame/data/res"
+
+	"github.com/isangeles/ignite/ai"
+)
+
+// Struct for offline AI simulation.
+type Simulation struct {
+	ai      *ai.AI
+	step    int64
+	time    int64
+	dummies []*Dummy
+}
+
+// Struct for scripted dummy character, dummy is not
+// controlled by the AI and walks along its path.
+type Dummy struct {
+	*character.Character
+	path  [][2]float64
+	point int
+}
+
+// ImportModule imports game module from the directory with
+// specified path.
+func ImportModule(path string) (*flame.Module, error) {
+	modData, err := data.ImportModuleDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to import module: %v", err)
+	}
+	return flame.NewModule(modData), nil
+}
+
+// New creates new simulation of specified AI with specified
+// fixed timestep in milliseconds.
+// Game of the AI should have no server set.
+func New(ai *ai.AI, step int64) *Simulation {
+	return &Simulation{ai: ai, step: step}
+}
+
+// AI returns simulated AI.
+func (s *Simulation) AI() *ai.AI {
+	return s.ai
+}
+
+// Time returns simulated time in milliseconds.
+func (s *Simulation) Time() int64 {
+	return s.time
+}
+
+// Dummies returns all dummy characters of the simulation.
+func (s *Simulation) Dummies() []*Dummy {
+	return s.dummies
+}
+
+// Update updates dummies, AI and game module by one timestep.
+func (s *Simulation) Update() {
+	for _, d := range s.dummies {
+		d.update()
+	}
+	s.ai.Update(s.step)
+	s.ai.Game().Update(s.step)
+	s.time += s.step
+}
+
+// Run updates the simulation without breaks until specified
+// duration in milliseconds is simulated or specified context
+// is done.
+func (s *Simulation) Run(ctx context.Context, duration int64) error {
+	for s.time < duration {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.Update()
+	}
+	return nil
+}
+
+// Control adds game module characters with specified IDs and
+// characters placed in areas with specified IDs to the AI.
+// Character ID could be followed by the serial value separated
+// with '#', e.g. 'wolf#0', otherwise all characters with the ID
+// are added.
+// Returns error if there is no character for any of specified
+// IDs or areas.
+func (s *Simulation) Control(ids, areas []string) error {
+	game := s.ai.Game()
+	for _, id := range ids {
+		id, serial, withSerial := strings.Cut(id, "#")
+		found := false
+		for _, c := range game.Chapter().Characters() {
+			if c.ID() != id || (withSerial && c.Serial() != serial) {
+				continue
+			}
+			s.control(c)
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("Character not found: %s", id)
+		}
+	}
+	for _, id := range areas {
+		found := false
+		for _, c := range game.Chapter().Characters() {
+			a := game.Chapter().ObjectArea(c)
+			if a == nil || a.ID() != id {
+				continue
+			}
+			s.control(c)
+			found = true
+		}
+		if !found {
+			return fmt.Errorf("No characters in area: %s", id)
+		}
+	}
+	return nil
+}
+
+// SpawnDummy creates new dummy character from the character data
+// with specified ID and places it in the area with specified ID at
+// the first point of specified path.
+// Dummy walks along the path points and starts again after
+// reaching the last point.
+func (s *Simulation) SpawnDummy(charID, areaID string, path ...[2]float64) (*Dummy, error) {
+	if len(path) < 1 {
+		return nil, fmt.Errorf("Empty dummy path")
+	}
+	charData := res.Character(charID)
+	if charData == nil {
+		return nil, fmt.Errorf("Character data not found: %s", charID)
+	}
+	var spawnArea *area.Area
+	for _, a := range s.ai.Game().Chapter().Areas() {
+		if a.ID() == areaID {
+			spawnArea = a
+		}
+	}
+	if spawnArea == nil {
+		return nil, fmt.Errorf("Area not found: %s", areaID)
+	}
+	d := Dummy{
+		Character: character.New(*charData),
+		path:      path,
+	}
+	d.SetPosition(path[0][0], path[0][1])
+	d.SetDestPoint(path[0][0], path[0][1])
+	spawnArea.AddObject(d.Character)
+	s.dummies = append(s.dummies, &d)
+	return &d, nil
+}
+
+// control adds specified character to the AI if it's not
+// already controlled.
+func (s *Simulation) control(c *character.Character) {
+	for _, aic := range s.ai.Game().Characters() {
+		if aic.ID() == c.ID() && aic.Serial() == c.Serial() {
+			return
+		}
+	}
+	s.ai.Game().AddCharacter(ai.NewCharacter(c, s.ai.Game()))
+}
+
+// update moves dummy to the next point of its path after
+// reaching current point.
+func (d *Dummy) update() {
+	if !d.Live() || d.Moving() {
+		return
+	}
+	d.point++
+	if d.point >= len(d.path) {
+		d.point = 0
+	}
+	d.SetDestPoint(d.path[d.point][0], d.path[d.point][1])
+}
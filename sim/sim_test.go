@@ -0,0 +1,71 @@
+/*
+ * sim_test.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package sim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/isangeles/flame"
+	"github.com/isangeles/flame/data/res"
+
+	"github.com/isangeles/ignite/ai"
+)
+
+// TestSimulationRun tests running simulation with fixed timestep.
+func TestSimulationRun(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	s := New(ai.New(ai.NewGame(mod, nil)), 16)
+	err := s.Run(context.Background(), 1000)
+	if err != nil {
+		t.Fatalf("Unable to run simulation: %v", err)
+	}
+	if s.Time() != 1008 {
+		t.Errorf("Invalid simulated time: %d != 1008", s.Time())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = s.Run(ctx, 2000)
+	if err != context.Canceled {
+		t.Errorf("Invalid run error: %v", err)
+	}
+	if s.Time() != 1008 {
+		t.Errorf("Simulation updated after context done")
+	}
+}
+
+// TestSimulationControl tests selecting characters not present
+// in the module.
+func TestSimulationControl(t *testing.T) {
+	mod := flame.NewModule(res.ModuleData{})
+	s := New(ai.New(ai.NewGame(mod, nil)), 16)
+	err := s.Control([]string{"wolf#0"}, nil)
+	if err == nil {
+		t.Errorf("No error for missing character")
+	}
+	err = s.Control(nil, []string{"area1"})
+	if err == nil {
+		t.Errorf("No error for empty area")
+	}
+}
@@ -0,0 +1,185 @@
+/*
+ * simulate.go
+ *
+ * Copyright 2026 Dariusz Sikora <ds@isangeles.dev>
+ *
+ * This program is free software; you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation; either version 2 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program; if not, write to the Free Software
+ * Foundation, Inc., 51 Franklin Street, Fifth Floor, Boston,
+ * MA 02110-1301, USA.
+ *
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/isangeles/ignite/ai"
+	"github.com/isangeles/ignite/sim"
+)
+
+// Type for repeatable string flag.
+type stringList []string
+
+// String returns all flag values separated with comma.
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+// Set adds specified value to the list.
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// simulate runs the AI with game module loaded from the disk
+// without server connection.
+func simulate(args []string) {
+	f := newFlags("simulate", false)
+	var chars, areas, dummies stringList
+	modPath := f.set.String("module", "", "path to the game module directory")
+	f.set.Var(&chars, "char", "ID of character to control, optionally with serial, e.g. 'wolf#0'")
+	f.set.Var(&areas, "area", "ID of area with characters to control")
+	f.set.Var(&dummies, "dummy", "dummy character with path, e.g. 'player;area1;10;10;100;10'")
+	step := f.set.Int64("step", ai.UpdateBreak.Milliseconds(), "simulation timestep in milliseconds")
+	duration := f.set.Int64("duration", 3600000, "simulated time in milliseconds")
+	report := f.set.Int64("report", 60000, "frequency of character reports in simulated milliseconds")
+	f.set.Parse(args)
+	conf, err := f.read()
+	if err == nil {
+		err = conf.ValidateOffline()
+	}
+	if err != nil {
+		log.Fatalf("Invalid config:\n%v", err)
+	}
+	if len(*modPath) < 1 {
+		log.Fatalf("Module path required")
+	}
+	if *step < 1 {
+		log.Fatalf("Invalid timestep: %d", *step)
+	}
+	slog.SetDefault(conf.NewLogger(os.Stderr))
+	mod, err := sim.ImportModule(*modPath)
+	if err != nil {
+		log.Fatalf("Unable to import module: %v", err)
+	}
+	game := ai.NewGame(mod, conf)
+	profiles, err := importProfiles(conf.ProfilesPath)
+	if err != nil {
+		slog.Error("unable to import NPC profiles", "error", err)
+	}
+	for _, p := range profiles {
+		game.AddProfile(p)
+	}
+	groups, err := ai.ImportGroupsDir(conf.ProfilesPath)
+	if err != nil {
+		slog.Error("unable to import NPC groups", "error", err)
+	}
+	for _, g := range groups {
+		game.AddGroup(g)
+	}
+	game.SetReputation(ai.NewReputation())
+	// Simulation doesn't modify reputation and state files.
+	gameAI := ai.New(game)
+	gameAI.SetReputationPath("")
+	gameAI.SetStatePath("")
+	if len(conf.TracePath) > 0 {
+		trace, err := ai.OpenTrace(conf.TracePath, conf.TraceFilter...)
+		if err != nil {
+			log.Fatalf("Unable to open decision trace: %v", err)
+		}
+		defer trace.Close()
+		gameAI.SetTrace(trace)
+	}
+	s := sim.New(gameAI, *step)
+	err = s.Control(chars, areas)
+	if err != nil {
+		log.Fatalf("Unable to select characters: %v", err)
+	}
+	for _, d := range dummies {
+		err := spawnDummy(s, d)
+		if err != nil {
+			log.Fatalf("Unable to spawn dummy: %s: %v", d, err)
+		}
+	}
+	slog.Info("simulation started", "characters", len(game.Characters()),
+		"dummies", len(s.Dummies()), "step", *step)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	start := time.Now()
+	for s.Time() < *duration {
+		next := s.Time() + *report
+		if *report < 1 || next > *duration {
+			next = *duration
+		}
+		err := s.Run(ctx, next)
+		if errors.Is(err, context.Canceled) {
+			slog.Info("simulation interrupted")
+			break
+		}
+		reportSimulation(s)
+	}
+	slog.Info("simulation finished", "time", s.Time(),
+		"real-time", time.Since(start).Milliseconds())
+}
+
+// spawnDummy spawns dummy character in specified simulation from
+// specified dummy flag value.
+// Dummy value contains character ID, area ID and XY positions of
+// the dummy path.
+func spawnDummy(s *sim.Simulation, value string) error {
+	values := strings.Split(value, ";")
+	if len(values) < 4 || len(values)%2 != 0 {
+		return fmt.Errorf("Invalid dummy value: expected character ID, area ID and XY positions")
+	}
+	path := make([][2]float64, 0)
+	for i := 2; i < len(values); i += 2 {
+		x, err := strconv.ParseFloat(values[i], 64)
+		if err != nil {
+			return fmt.Errorf("Unable to parse X position: %v", err)
+		}
+		y, err := strconv.ParseFloat(values[i+1], 64)
+		if err != nil {
+			return fmt.Errorf("Unable to parse Y position: %v", err)
+		}
+		path = append(path, [2]float64{x, y})
+	}
+	_, err := s.SpawnDummy(values[0], values[1], path...)
+	return err
+}
+
+// reportSimulation logs positions and targets of all characters
+// controlled by the simulated AI.
+func reportSimulation(s *sim.Simulation) {
+	for _, c := range s.AI().Game().Characters() {
+		x, y := c.Position()
+		target := ""
+		if len(c.Targets()) > 0 {
+			target = c.Targets()[0].ID() + "#" + c.Targets()[0].Serial()
+		}
+		slog.Info("simulation report", "time", s.Time(), "char-id", c.ID(),
+			"char-serial", c.Serial(), "pos-x", x, "pos-y", y, "health", c.Health(),
+			"max-health", c.MaxHealth(), "target", target)
+	}
+}